@@ -110,6 +110,14 @@ var (
 		Name:  "nostack",
 		Usage: "disable stack output",
 	}
+	ABIFlag = cli.StringFlag{
+		Name:  "abi",
+		Usage: "JSON file with the contract ABI, names the arguments in taint findings",
+	}
+	ImplicitFlowFlag = cli.BoolFlag{
+		Name:  "implicit",
+		Usage: "propagate taint from tainted branch conditions to the values computed under them",
+	}
 )
 
 func init() {
@@ -133,6 +141,8 @@ func init() {
 		ReceiverFlag,
 		DisableMemoryFlag,
 		DisableStackFlag,
+		ABIFlag,
+		ImplicitFlowFlag,
 	}
 	app.Commands = []cli.Command{
 		compileCommand,
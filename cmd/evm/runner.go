@@ -27,6 +27,7 @@ import (
 	"runtime/pprof"
 	"time"
 
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/cmd/evm/internal/compiler"
 	"github.com/ethereum/go-ethereum/cmd/utils"
 	"github.com/ethereum/go-ethereum/common"
@@ -69,6 +70,41 @@ func readGenesis(genesisPath string) *core.Genesis {
 	return genesis
 }
 
+// readTaintArgs reads the given JSON format ABI file and returns the
+// parameters of the method called by input.
+func readTaintArgs(abiPath string, input []byte) []vm.TaintArg {
+	file, err := os.Open(abiPath)
+	if err != nil {
+		utils.Fatalf("Failed to read ABI file: %v", err)
+	}
+	defer file.Close()
+
+	definition, err := abi.JSON(file)
+	if err != nil {
+		utils.Fatalf("invalid ABI file: %v", err)
+	}
+	if len(input) < 4 {
+		return nil
+	}
+	method, err := definition.MethodById(input[:4])
+	if err != nil {
+		utils.Fatalf("Failed to find called method: %v", err)
+	}
+	args := make([]vm.TaintArg, len(method.Inputs))
+	for i, input := range method.Inputs {
+		args[i].Name = input.Name
+		switch input.Type.T {
+		case abi.SliceTy:
+			args[i].Dynamic = true
+		case abi.StringTy, abi.BytesTy:
+			args[i].Dynamic, args[i].Packed = true, true
+		case abi.ArrayTy:
+			args[i].Words = input.Type.Size
+		}
+	}
+	return args
+}
+
 func runCmd(ctx *cli.Context) error {
 	glogger := log.NewGlogHandler(log.StreamHandler(os.Stderr, log.TerminalFormat(false)))
 	glogger.Verbosity(log.Lvl(ctx.GlobalInt(VerbosityFlag.Name)))
@@ -164,8 +200,9 @@ func runCmd(ctx *cli.Context) error {
 		Value:       utils.GlobalBig(ctx, ValueFlag.Name),
 		BlockNumber: new(big.Int).SetUint64(blockNumber),
 		EVMConfig: vm.Config{
-			Tracer: tracer,
-			Debug:  ctx.GlobalBool(DebugFlag.Name) || ctx.GlobalBool(MachineFlag.Name),
+			Tracer:            tracer,
+			Debug:             ctx.GlobalBool(DebugFlag.Name) || ctx.GlobalBool(MachineFlag.Name),
+			TaintImplicitFlow: ctx.GlobalBool(ImplicitFlowFlag.Name),
 		},
 	}
 
@@ -189,12 +226,16 @@ func runCmd(ctx *cli.Context) error {
 	var leftOverGas uint64
 	if ctx.GlobalBool(CreateFlag.Name) {
 		input := append(code, common.Hex2Bytes(ctx.GlobalString(InputFlag.Name))...)
-		ret, _, leftOverGas, err = runtime.Create(input, &runtimeConfig)
+		ret, _, leftOverGas, _, err = runtime.Create(input, &runtimeConfig)
 	} else {
 		if len(code) > 0 {
 			statedb.SetCode(receiver, code)
 		}
-		ret, leftOverGas, err = runtime.Call(receiver, common.Hex2Bytes(ctx.GlobalString(InputFlag.Name)), &runtimeConfig)
+		input := common.Hex2Bytes(ctx.GlobalString(InputFlag.Name))
+		if abiPath := ctx.GlobalString(ABIFlag.Name); abiPath != "" {
+			runtimeConfig.EVMConfig.TaintArgs = readTaintArgs(abiPath, input)
+		}
+		ret, leftOverGas, _, err = runtime.Call(receiver, input, &runtimeConfig)
 	}
 	execTime := time.Since(tstart)
 
@@ -111,6 +111,15 @@ type (
 		account       *common.Address
 		key, prevalue common.Hash
 	}
+	stateTaintChange struct {
+		account *common.Address
+		key     common.Hash
+		prev    int
+	}
+	stateTaintsReset struct {
+		account *common.Address
+		prev    map[common.Hash]int
+	}
 	codeChange struct {
 		account            *common.Address
 		prevcode, prevhash []byte
@@ -203,6 +212,22 @@ func (ch storageChange) dirtied() *common.Address {
 	return ch.account
 }
 
+func (ch stateTaintChange) revert(s *StateDB) {
+	s.setStateTaint(*ch.account, ch.key, ch.prev)
+}
+
+func (ch stateTaintChange) dirtied() *common.Address {
+	return nil
+}
+
+func (ch stateTaintsReset) revert(s *StateDB) {
+	s.stateTaints[*ch.account] = ch.prev
+}
+
+func (ch stateTaintsReset) dirtied() *common.Address {
+	return nil
+}
+
 func (ch refundChange) revert(s *StateDB) {
 	s.refund = ch.prev
 }
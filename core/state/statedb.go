@@ -74,6 +74,10 @@ type StateDB struct {
 
 	preimages map[common.Hash][]byte
 
+	// Shadow taint of the storage slots, used by the taint analysis of the
+	// EVM. It is not part of the consensus state.
+	stateTaints map[common.Address]map[common.Hash]int
+
 	// Journal of state modifications. This is the backbone of
 	// Snapshot and RevertToSnapshot.
 	journal        *journal
@@ -96,6 +100,7 @@ func New(root common.Hash, db Database) (*StateDB, error) {
 		stateObjectsDirty: make(map[common.Address]struct{}),
 		logs:              make(map[common.Hash][]*types.Log),
 		preimages:         make(map[common.Hash][]byte),
+		stateTaints:       make(map[common.Address]map[common.Hash]int),
 		journal:           newJournal(),
 	}, nil
 }
@@ -244,6 +249,11 @@ func (self *StateDB) GetState(addr common.Address, bhash common.Hash) common.Has
 	return common.Hash{}
 }
 
+// GetStateTaint returns the taint flag of the value stored in the given slot.
+func (self *StateDB) GetStateTaint(addr common.Address, key common.Hash) int {
+	return self.stateTaints[addr][key]
+}
+
 // Database retrieves the low level database supporting the lower level trie ops.
 func (self *StateDB) Database() Database {
 	return self.db
@@ -316,6 +326,40 @@ func (self *StateDB) SetState(addr common.Address, key, value common.Hash) {
 	}
 }
 
+// SetStateTaint records the taint flag of the value stored in the given slot.
+// The change is journalled, so reverting a snapshot also reverts the taint.
+func (self *StateDB) SetStateTaint(addr common.Address, key common.Hash, taint int) {
+	self.journal.append(stateTaintChange{
+		account: &addr,
+		key:     key,
+		prev:    self.GetStateTaint(addr, key),
+	})
+	self.setStateTaint(addr, key, taint)
+}
+
+func (self *StateDB) setStateTaint(addr common.Address, key common.Hash, taint int) {
+	if taint == 0 {
+		delete(self.stateTaints[addr], key)
+		if len(self.stateTaints[addr]) == 0 {
+			delete(self.stateTaints, addr)
+		}
+		return
+	}
+	if self.stateTaints[addr] == nil {
+		self.stateTaints[addr] = make(map[common.Hash]int)
+	}
+	self.stateTaints[addr][key] = taint
+}
+
+// resetStateTaints drops the taint flags of every slot of the account, its
+// storage is gone once it suicides or is created anew.
+func (self *StateDB) resetStateTaints(addr common.Address) {
+	if prev := self.stateTaints[addr]; prev != nil {
+		self.journal.append(stateTaintsReset{account: &addr, prev: prev})
+		delete(self.stateTaints, addr)
+	}
+}
+
 // Suicide marks the given account as suicided.
 // This clears the account balance.
 //
@@ -333,6 +377,7 @@ func (self *StateDB) Suicide(addr common.Address) bool {
 	})
 	stateObject.markSuicided()
 	stateObject.data.Balance = new(big.Int)
+	self.resetStateTaints(addr)
 
 	return true
 }
@@ -409,6 +454,7 @@ func (self *StateDB) createObject(addr common.Address) (newobj, prev *stateObjec
 	} else {
 		self.journal.append(resetObjectChange{prev: prev})
 	}
+	self.resetStateTaints(addr)
 	self.setStateObject(newobj)
 	return newobj, prev
 }
@@ -467,6 +513,7 @@ func (self *StateDB) Copy() *StateDB {
 		logs:              make(map[common.Hash][]*types.Log, len(self.logs)),
 		logSize:           self.logSize,
 		preimages:         make(map[common.Hash][]byte),
+		stateTaints:       make(map[common.Address]map[common.Hash]int, len(self.stateTaints)),
 		journal:           newJournal(),
 	}
 	// Copy the dirty states, logs, and preimages
@@ -497,6 +544,12 @@ func (self *StateDB) Copy() *StateDB {
 	for hash, preimage := range self.preimages {
 		state.preimages[hash] = preimage
 	}
+	for addr, taints := range self.stateTaints {
+		state.stateTaints[addr] = make(map[common.Hash]int, len(taints))
+		for key, taint := range taints {
+			state.stateTaints[addr][key] = taint
+		}
+	}
 	return state
 }
 
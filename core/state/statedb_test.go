@@ -237,6 +237,15 @@ func newTestAction(addr common.Address, r *rand.Rand) testAction {
 			},
 			args: make([]int64, 2),
 		},
+		{
+			name: "SetStateTaint",
+			fn: func(a testAction, s *StateDB) {
+				var key common.Hash
+				binary.BigEndian.PutUint16(key[:], uint16(a.args[0]))
+				s.SetStateTaint(addr, key, int(a.args[1]))
+			},
+			args: make([]int64, 2),
+		},
 		{
 			name: "SetCode",
 			fn: func(a testAction, s *StateDB) {
@@ -379,6 +388,7 @@ func (test *snapshotTest) checkEqual(state, checkstate *StateDB) error {
 		checkeq("GetCode", state.GetCode(addr), checkstate.GetCode(addr))
 		checkeq("GetCodeHash", state.GetCodeHash(addr), checkstate.GetCodeHash(addr))
 		checkeq("GetCodeSize", state.GetCodeSize(addr), checkstate.GetCodeSize(addr))
+		checkeq("StateTaints", state.stateTaints[addr], checkstate.stateTaints[addr])
 		// Check storage.
 		if obj := state.getStateObject(addr); obj != nil {
 			state.ForEachStorage(addr, func(key, val common.Hash) bool {
@@ -435,3 +445,26 @@ func TestCopyOfCopy(t *testing.T) {
 		t.Fatalf("2nd copy fail, expected 42, got %v", got)
 	}
 }
+
+func TestStateTaintReset(t *testing.T) {
+	sdb, _ := New(common.Hash{}, NewDatabase(ethdb.NewMemDatabase()))
+	addr := common.HexToAddress("aaaa")
+	key := common.HexToHash("01")
+	sdb.SetState(addr, key, common.HexToHash("02"))
+	sdb.SetStateTaint(addr, key, 1)
+
+	for _, reset := range []func(){
+		func() { sdb.Suicide(addr) },
+		func() { sdb.CreateAccount(addr) },
+	} {
+		snapshot := sdb.Snapshot()
+		reset()
+		if got := sdb.GetStateTaint(addr, key); got != 0 {
+			t.Errorf("expected the taint to be dropped, got %d", got)
+		}
+		sdb.RevertToSnapshot(snapshot)
+		if got := sdb.GetStateTaint(addr, key); got != 1 {
+			t.Errorf("expected the taint to be restored, got %d", got)
+		}
+	}
+}
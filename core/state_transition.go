@@ -210,7 +210,7 @@ func (st *StateTransition) TransitionDb() (ret []byte, usedGas uint64, failed bo
 	} else {
 		// Increment the nonce for the next transaction
 		st.state.SetNonce(msg.From(), st.state.GetNonce(sender.Address())+1)
-		ret, _, st.gas, vmerr = evm.Call(sender, st.to(), st.data, st.gas, st.value)
+		ret, _, st.gas, vmerr = evm.Call(sender, st.to(), st.data, nil, st.gas, st.value)
 	}
 	if vmerr != nil {
 		log.Debug("VM returned with error", "err", vmerr)
@@ -56,6 +56,8 @@ type Contract struct {
 	CodeAddr *common.Address
 	Input    []byte
 
+	InputTaint []int // taint of every byte of Input
+
 	Gas   uint64
 	value *big.Int
 
@@ -17,7 +17,6 @@
 package vm
 
 import (
-	"fmt"
 	"math/big"
 	"sync/atomic"
 	"time"
@@ -47,30 +46,22 @@ func addFlagInReturn(ret1 []byte, ret2 error) ([]byte, []int, error) {
 	return ret1, temp_returnFlag, ret2
 }
 
-func printTaintFlag() {
-	if global_taint_flag&OVERFLOW_FLAG > 0 {
-		fmt.Println("taint flag: overflow")
-	} else if global_taint_flag&PROTECTED_OVERFLOW_FLAG > 0 {
-		fmt.Println("taint flag: protected overflow")
-	} else if global_taint_flag&POTENTIAL_OVERFLOW_FLAG > 0 {
-		fmt.Println("taint flag: potential overflow")
-	} else {
-		fmt.Println("taint flag: safe")
-	}
-}
-
 // run runs the given contract and takes care of running precompiles with a fallback to the byte code interpreter.
-func run(evm *EVM, contract *Contract, input []byte) ([]byte, []int, error) {
+func run(evm *EVM, contract *Contract, input []byte, inputTaint []int) ([]byte, []int, error) {
 	if contract.CodeAddr != nil {
 		precompiles := PrecompiledContractsHomestead
 		if evm.ChainConfig().IsByzantium(evm.BlockNumber) {
 			precompiles = PrecompiledContractsByzantium
 		}
 		if p := precompiles[*contract.CodeAddr]; p != nil {
+			if !evm.vmConfig.TaintAnalysis {
+				ret, err := RunPrecompiledContract(p, input, contract)
+				return ret, nil, err
+			}
 			return addFlagInReturn(RunPrecompiledContract(p, input, contract))
 		}
 	}
-	return evm.interpreter.Run(contract, input)
+	return evm.interpreter.Run(contract, input, inputTaint)
 }
 
 // Context provides the EVM with auxiliary information. Once provided
@@ -130,17 +121,25 @@ type EVM struct {
 	// available gas is calculated in gasCall* according to the 63/64 rule and later
 	// applied in opCall*.
 	callGasTemp uint64
+	// taintReport collects the taint analysis results of the current
+	// top-level call or create. It is reset whenever a new one starts.
+	taintReport *TaintReport
 }
 
 // NewEVM returns a new EVM. The returned EVM is not thread safe and should
 // only ever be used *once*.
 func NewEVM(ctx Context, statedb StateDB, chainConfig *params.ChainConfig, vmConfig Config) *EVM {
+	// the verifier build checks the taint shadow of every execution
+	if verifyTaint {
+		vmConfig.TaintAnalysis = true
+	}
 	evm := &EVM{
 		Context:     ctx,
 		StateDB:     statedb,
 		vmConfig:    vmConfig,
 		chainConfig: chainConfig,
 		chainRules:  chainConfig.Rules(ctx.BlockNumber),
+		taintReport: NewTaintReport(),
 	}
 
 	evm.interpreter = NewInterpreter(evm, vmConfig)
@@ -157,10 +156,17 @@ func (evm *EVM) Cancel() {
 // parameters. It also handles any necessary value transfer required and takes
 // the necessary steps to create accounts and reverses the state in case of an
 // execution error or failed value transfer.
-func (evm *EVM) Call(caller ContractRef, addr common.Address, input []byte, gas uint64, value *big.Int) (ret []byte, taintFlag []int, leftOverGas uint64, err error) {
+//
+// The inputTaint holds the taint of every byte of the input, nil marks the
+// whole input as untrusted calldata.
+func (evm *EVM) Call(caller ContractRef, addr common.Address, input []byte, inputTaint []int, gas uint64, value *big.Int) (ret []byte, taintFlag []int, leftOverGas uint64, err error) {
 	if evm.vmConfig.NoRecursion && evm.depth > 0 {
 		return nil, nil, gas, nil
 	}
+	// Start a fresh taint report for every top-level call
+	if evm.vmConfig.TaintAnalysis && evm.depth == 0 {
+		evm.taintReport = newCallTaintReport(input, evm.vmConfig.TaintArgs)
+	}
 
 	// Fail if we're trying to execute above the call depth limit
 	if evm.depth > int(params.CallCreateDepth) {
@@ -205,10 +211,10 @@ func (evm *EVM) Call(caller ContractRef, addr common.Address, input []byte, gas
 
 		defer func() { // Lazy evaluation of the parameters
 			evm.vmConfig.Tracer.CaptureEnd(ret, gas-contract.Gas, time.Since(start), err)
-			printTaintFlag()
+			evm.taintReport.Print()
 		}()
 	}
-	ret, taintFlag, err = run(evm, contract, input)
+	ret, taintFlag, err = run(evm, contract, input, inputTaint)
 
 	// When an error was returned by the EVM or when setting the creation code
 	// above we revert to the snapshot and consume any gas remaining. Additionally
@@ -229,10 +235,14 @@ func (evm *EVM) Call(caller ContractRef, addr common.Address, input []byte, gas
 //
 // CallCode differs from Call in the sense that it executes the given address'
 // code with the caller as context.
-func (evm *EVM) CallCode(caller ContractRef, addr common.Address, input []byte, gas uint64, value *big.Int) (ret []byte, taintFlag []int, leftOverGas uint64, err error) {
+func (evm *EVM) CallCode(caller ContractRef, addr common.Address, input []byte, inputTaint []int, gas uint64, value *big.Int) (ret []byte, taintFlag []int, leftOverGas uint64, err error) {
 	if evm.vmConfig.NoRecursion && evm.depth > 0 {
 		return nil, nil, gas, nil
 	}
+	// Start a fresh taint report for every top-level call
+	if evm.vmConfig.TaintAnalysis && evm.depth == 0 {
+		evm.taintReport = newCallTaintReport(input, evm.vmConfig.TaintArgs)
+	}
 
 	// Fail if we're trying to execute above the call depth limit
 	if evm.depth > int(params.CallCreateDepth) {
@@ -253,7 +263,7 @@ func (evm *EVM) CallCode(caller ContractRef, addr common.Address, input []byte,
 	contract := NewContract(caller, to, value, gas)
 	contract.SetCallCode(&addr, evm.StateDB.GetCodeHash(addr), evm.StateDB.GetCode(addr))
 
-	ret, taintFlag, err = run(evm, contract, input)
+	ret, taintFlag, err = run(evm, contract, input, inputTaint)
 	if err != nil {
 		evm.StateDB.RevertToSnapshot(snapshot)
 		if err != errExecutionReverted {
@@ -268,10 +278,14 @@ func (evm *EVM) CallCode(caller ContractRef, addr common.Address, input []byte,
 //
 // DelegateCall differs from CallCode in the sense that it executes the given address'
 // code with the caller as context and the caller is set to the caller of the caller.
-func (evm *EVM) DelegateCall(caller ContractRef, addr common.Address, input []byte, gas uint64) (ret []byte, taintFlag []int, leftOverGas uint64, err error) {
+func (evm *EVM) DelegateCall(caller ContractRef, addr common.Address, input []byte, inputTaint []int, gas uint64) (ret []byte, taintFlag []int, leftOverGas uint64, err error) {
 	if evm.vmConfig.NoRecursion && evm.depth > 0 {
 		return nil, nil, gas, nil
 	}
+	// Start a fresh taint report for every top-level call
+	if evm.vmConfig.TaintAnalysis && evm.depth == 0 {
+		evm.taintReport = newCallTaintReport(input, evm.vmConfig.TaintArgs)
+	}
 	// Fail if we're trying to execute above the call depth limit
 	if evm.depth > int(params.CallCreateDepth) {
 		return nil, nil, gas, ErrDepth
@@ -286,7 +300,7 @@ func (evm *EVM) DelegateCall(caller ContractRef, addr common.Address, input []by
 	contract := NewContract(caller, to, nil, gas).AsDelegate()
 	contract.SetCallCode(&addr, evm.StateDB.GetCodeHash(addr), evm.StateDB.GetCode(addr))
 
-	ret, taintFlag, err = run(evm, contract, input)
+	ret, taintFlag, err = run(evm, contract, input, inputTaint)
 	if err != nil {
 		evm.StateDB.RevertToSnapshot(snapshot)
 		if err != errExecutionReverted {
@@ -300,10 +314,14 @@ func (evm *EVM) DelegateCall(caller ContractRef, addr common.Address, input []by
 // as parameters while disallowing any modifications to the state during the call.
 // Opcodes that attempt to perform such modifications will result in exceptions
 // instead of performing the modifications.
-func (evm *EVM) StaticCall(caller ContractRef, addr common.Address, input []byte, gas uint64) (ret []byte, taintFlag []int, leftOverGas uint64, err error) {
+func (evm *EVM) StaticCall(caller ContractRef, addr common.Address, input []byte, inputTaint []int, gas uint64) (ret []byte, taintFlag []int, leftOverGas uint64, err error) {
 	if evm.vmConfig.NoRecursion && evm.depth > 0 {
 		return nil, nil, gas, nil
 	}
+	// Start a fresh taint report for every top-level call
+	if evm.vmConfig.TaintAnalysis && evm.depth == 0 {
+		evm.taintReport = newCallTaintReport(input, evm.vmConfig.TaintArgs)
+	}
 	// Fail if we're trying to execute above the call depth limit
 	if evm.depth > int(params.CallCreateDepth) {
 		return nil, nil, gas, ErrDepth
@@ -329,7 +347,7 @@ func (evm *EVM) StaticCall(caller ContractRef, addr common.Address, input []byte
 	// When an error was returned by the EVM or when setting the creation code
 	// above we revert to the snapshot and consume any gas remaining. Additionally
 	// when we're in Homestead this also counts for code storage gas errors.
-	ret, taintFlag, err = run(evm, contract, input)
+	ret, taintFlag, err = run(evm, contract, input, inputTaint)
 	if err != nil {
 		evm.StateDB.RevertToSnapshot(snapshot)
 		if err != errExecutionReverted {
@@ -339,8 +357,12 @@ func (evm *EVM) StaticCall(caller ContractRef, addr common.Address, input []byte
 	return ret, taintFlag, contract.Gas, err
 }
 
-// Create creates a new contract using code as deployment code.
-func (evm *EVM) Create(caller ContractRef, code []byte, gas uint64, value *big.Int) (ret []byte, taintFlag []int, contractAddr common.Address, leftOverGas uint64, err error) {
+// create creates a new contract using code as deployment code.
+func (evm *EVM) create(caller ContractRef, code []byte, gas uint64, value *big.Int, contractAddr common.Address) ([]byte, []int, common.Address, uint64, error) {
+	// Start a fresh taint report for every top-level create
+	if evm.vmConfig.TaintAnalysis && evm.depth == 0 {
+		evm.taintReport = NewTaintReport()
+	}
 
 	// Depth check execution. Fail if we're trying to execute above the
 	// limit.
@@ -350,11 +372,10 @@ func (evm *EVM) Create(caller ContractRef, code []byte, gas uint64, value *big.I
 	if !evm.CanTransfer(evm.StateDB, caller.Address(), value) {
 		return nil, nil, common.Address{}, gas, ErrInsufficientBalance
 	}
-	// Ensure there's no existing contract already at the designated address
 	nonce := evm.StateDB.GetNonce(caller.Address())
 	evm.StateDB.SetNonce(caller.Address(), nonce+1)
 
-	contractAddr = crypto.CreateAddress(caller.Address(), nonce)
+	// Ensure there's no existing contract already at the designated address
 	contractHash := evm.StateDB.GetCodeHash(contractAddr)
 	if evm.StateDB.GetNonce(contractAddr) != 0 || (contractHash != (common.Hash{}) && contractHash != emptyCodeHash) {
 		return nil, nil, common.Address{}, 0, ErrContractAddressCollision
@@ -382,7 +403,7 @@ func (evm *EVM) Create(caller ContractRef, code []byte, gas uint64, value *big.I
 	}
 	start := time.Now()
 
-	ret, taintFlag, err = run(evm, contract, nil)
+	ret, taintFlag, err := run(evm, contract, nil, nil)
 
 	// check whether the max code size has been exceeded
 	maxCodeSizeExceeded := evm.ChainConfig().IsEIP158(evm.BlockNumber) && len(ret) > params.MaxCodeSize
@@ -419,8 +440,28 @@ func (evm *EVM) Create(caller ContractRef, code []byte, gas uint64, value *big.I
 	return ret, taintFlag, contractAddr, contract.Gas, err
 }
 
+// Create creates a new contract using code as deployment code.
+func (evm *EVM) Create(caller ContractRef, code []byte, gas uint64, value *big.Int) (ret []byte, taintFlag []int, contractAddr common.Address, leftOverGas uint64, err error) {
+	contractAddr = crypto.CreateAddress(caller.Address(), evm.StateDB.GetNonce(caller.Address()))
+	return evm.create(caller, code, gas, value, contractAddr)
+}
+
+// Create2 creates a new contract using code as deployment code.
+//
+// Create2 differs from Create in that the contract is initialised at the
+// address sha3(0xff ++ msg.sender ++ salt ++ sha3(init_code))[12:] instead of
+// the usual sender-and-nonce-hash.
+func (evm *EVM) Create2(caller ContractRef, code []byte, gas uint64, value *big.Int, salt *big.Int) (ret []byte, taintFlag []int, contractAddr common.Address, leftOverGas uint64, err error) {
+	contractAddr = crypto.CreateAddress2(caller.Address(), common.BigToHash(salt), crypto.Keccak256(code))
+	return evm.create(caller, code, gas, value, contractAddr)
+}
+
 // ChainConfig returns the environment's chain configuration
 func (evm *EVM) ChainConfig() *params.ChainConfig { return evm.chainConfig }
 
 // Interpreter returns the EVM interpreter
 func (evm *EVM) Interpreter() *Interpreter { return evm.interpreter }
+
+// TaintReport returns the taint analysis results of the last top-level
+// call or create.
+func (evm *EVM) TaintReport() *TaintReport { return evm.taintReport }
@@ -37,288 +37,54 @@ var (
 	errMaxCodeSizeExceeded   = errors.New("evm: max code size exceeded")
 )
 
-func BigPow(a, b int64) *big.Int {
-	r := big.NewInt(a)
-	return r.Exp(r, big.NewInt(b), nil)
-}
-
-func U256(x *big.Int) *big.Int {
-	var tt256m1 = new(big.Int).Sub(BigPow(2, 256), big.NewInt(1))
-	return x.And(x, tt256m1)
-}
-
-func checkExpOverflow(base, exponent *big.Int) bool {
-	const wordBits = 32 << (uint64(^big.Word(0)) >> 63)
-	result := big.NewInt(1)
-
-	for _, word := range exponent.Bits() {
-		for i := 0; i < wordBits; i++ {
-			if word&1 == 1 {
-				temp_x := new(big.Int).Set(result)
-				temp_y := new(big.Int).Set(base)
-
-				U256(result.Mul(result, base))
-
-				temp_res := new(big.Int).Set(result)
-				if math.U256(temp_res.Div(temp_res, temp_x)).Cmp(temp_y) != 0 {
-					return true
-				}
-			}
-			temp_x := new(big.Int).Set(base)
-			temp_y := new(big.Int).Set(base)
-
-			U256(base.Mul(base, base))
-
-			temp_res := new(big.Int).Set(base)
-			if math.U256(temp_res.Div(temp_res, temp_x)).Cmp(temp_y) != 0 {
-				return true
-			}
-
-			word >>= 1
-		}
+// checkShlOverflow reports whether shifting value left by shift bits drops
+// set bits.
+func checkShlOverflow(shift, value *big.Int) bool {
+	if value.Sign() == 0 {
+		return false
 	}
-	return false
+	return shift.Cmp(common.Big256) >= 0 || uint64(value.BitLen())+shift.Uint64() > 256
 }
 
-func checkAddProtection(pc *uint64, contract *Contract) bool {
-	var n = *pc
-	var op OpCode
-	//fmt.Println(contract.GetOp(n))
-	for {
-		op = contract.GetOp(n)
-		if op == JUMP || op == JUMPI || op == JUMPDEST {
-			break
-		}
-		if op == CREATE || op == CALL || op == CALLCODE || op == RETURN || op == DELEGATECALL || op == STATICCALL || op == REVERT || op == SELFDESTRUCT {
-			break
-		}
-
-		// safemath pattern: contract3.safeadd1
-		// assert(c >= a)
-		if op == DUP4 && contract.GetOp(n+1) == DUP2 && contract.GetOp(n+2) == LT && contract.GetOp(n+3) == ISZERO {
-			return true
-		}
-
-		// safemath pattern: contract3.safeadd2
-		// if(a + b >= a)
-		if op == ADD && contract.GetOp(n+1) == LT && contract.GetOp(n+2) == ISZERO {
-			return true
-		}
-		if op == ADD && contract.GetOp(n+1) == GT && contract.GetOp(n+2) == JUMPDEST && contract.GetOp(n+3) == ISZERO {
-			return true
-		}
-
-		n++
-	}
-
-	n = *pc
-	for {
-		op = contract.GetOp(n)
-		if op == JUMP || op == JUMPI || op == JUMPDEST {
-			break
-		}
-		if op == CREATE || op == CALL || op == CALLCODE || op == RETURN || op == DELEGATECALL || op == STATICCALL || op == REVERT || op == SELFDESTRUCT {
-			break
-		}
-
-		// safemath pattern: contract3.safeadd2
-		// if(a + b >= a)
-		if op == ADD && contract.GetOp(n+1) == LT && contract.GetOp(n+2) == ISZERO {
-			return true
-		}
-
-		// safemath pattern: contract3.safeadd3
-		// if (a > MAX_UINT256 - b) throw;
-		if op == ADD && contract.GetOp(n-15) == SUB && contract.GetOp(n-14) == DUP4 && contract.GetOp(n-13) == GT && contract.GetOp(n-12) == ISZERO {
-			return true
-		}
-
-		n--
-	}
-	return false
-}
-
-func checkSubProtection(pc *uint64, contract *Contract) bool {
-	var n = *pc
-	var op OpCode
-	//fmt.Println(contract.GetOp(n))
-
-	n = *pc
-	for {
-		op = contract.GetOp(n)
-		if op == JUMP || op == JUMPI || op == JUMPDEST {
-			break
-		}
-		if op == CREATE || op == CALL || op == CALLCODE || op == RETURN || op == DELEGATECALL || op == STATICCALL || op == REVERT || op == SELFDESTRUCT {
-			break
-		}
-
-		// safemath pattern: contract4.safesub1
-		// assert(b<=a)
-		if op == SUB && contract.GetOp(n-14) == DUP3 && contract.GetOp(n-13) == DUP3 && contract.GetOp(n-12) == GT && contract.GetOp(n-11) == ISZERO {
-			return true
-		}
-
-		// safemath pattern: contract4.safesub2
-		// if(a>=b)
-		if op == SUB && contract.GetOp(n-11) == DUP2 && contract.GetOp(n-10) == DUP4 && contract.GetOp(n-9) == LT && contract.GetOp(n-8) == ISZERO {
-			return true
-		}
-
-		// safemath pattern: contract4.safesub2
-		// if (a < b) throw;
-		if op == SUB && contract.GetOp(n-15) == DUP2 && contract.GetOp(n-14) == DUP4 && contract.GetOp(n-13) == LT && contract.GetOp(n-12) == ISZERO {
-			return true
-		}
-
-		n--
-	}
-	return false
-}
-
-func checkMulProtection(pc *uint64, contract *Contract) bool {
-	var n = *pc
-	var op OpCode
-	//fmt.Println(contract.GetOp(n))
-	for {
-		op = contract.GetOp(n)
-		if op == JUMP || op == JUMPI || op == JUMPDEST {
-			break
-		}
-		if op == CREATE || op == CALL || op == CALLCODE || op == RETURN || op == DELEGATECALL || op == STATICCALL || op == REVERT || op == SELFDESTRUCT {
-			break
-		}
-
-		// safemath pattern: contract5.safemul1
-		// assert(a == 0 || c / a == b);
-		if op == PUSH1 && contract.GetOp(n+2) == DUP5 && contract.GetOp(n+3) == EQ && contract.GetOp(n+22) == DIV && contract.GetOp(n+23) == EQ {
-			return true
-		}
-
-		n++
+// checkExpOverflow reports whether base ** exponent does not fit in 256
+// bits.
+func checkExpOverflow(base, exponent *big.Int) bool {
+	// zero and one stay below two, any base to the power zero or one fits
+	if base.Cmp(common.Big1) <= 0 || exponent.Cmp(common.Big1) <= 0 {
+		return false
 	}
-
-	n = *pc
-	for {
-		op = contract.GetOp(n)
-		if op == JUMP || op == JUMPI || op == JUMPDEST {
-			break
-		}
-		if op == CREATE || op == CALL || op == CALLCODE || op == RETURN || op == DELEGATECALL || op == STATICCALL || op == REVERT || op == SELFDESTRUCT {
-			break
-		}
-
-		// safemath pattern: contract5.safemul2
-		// if (x > MAX_UINT256 / y) throw;
-		if op == MUL && contract.GetOp(n-15) == DIV && contract.GetOp(n-14) == DUP4 && contract.GetOp(n-13) == GT && contract.GetOp(n-12) == ISZERO {
-			return true
-		}
-
-		n--
+	// the result is at least 2^((base bits - 1) * exponent)
+	if exponent.Cmp(common.Big256) >= 0 || uint64(base.BitLen()-1)*exponent.Uint64() >= 256 {
+		return true
 	}
-	return false
+	return new(big.Int).Exp(base, exponent, nil).BitLen() > 256
 }
 
-func opAdd(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opAdd(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	x, y := stack.pop(), stack.peek()
-	temp_x := new(big.Int).Set(x)
-	temp_y := new(big.Int).Set(y)
 	math.U256(y.Add(x, y))
 
 	evm.interpreter.intPool.put(x)
-
-	temp_res := new(big.Int).Set(y)
-	temp_flag := SAFE_FLAG
-
-	tx, ty := taint_stack.pop(), taint_stack.pop()
-	if (tx|ty)&CALLDATA_FLAG > 0 {
-		if temp_res.Cmp(temp_x) < 0 || temp_res.Cmp(temp_y) < 0 {
-			if checkAddProtection(pc, contract) {
-				temp_flag |= PROTECTED_OVERFLOW_FLAG
-				global_taint_flag |= PROTECTED_OVERFLOW_FLAG
-			} else {
-				temp_flag |= OVERFLOW_FLAG
-				global_taint_flag |= OVERFLOW_FLAG
-			}
-		}
-		temp_flag |= POTENTIAL_OVERFLOW_FLAG
-		global_taint_flag |= POTENTIAL_OVERFLOW_FLAG
-	}
-
-	taint_stack.push(temp_flag)
-
-	evm.interpreter.taintIntPool.put(tx)
-
 	return nil, nil, nil
 }
 
-func opSub(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opSub(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	x, y := stack.pop(), stack.peek()
-	temp_x := new(big.Int).Set(x)
-	temp_y := new(big.Int).Set(y)
 	math.U256(y.Sub(x, y))
 
 	evm.interpreter.intPool.put(x)
-
-	//temp_res := new(big.Int).Set(y)
-	temp_flag := SAFE_FLAG
-
-	tx, ty := taint_stack.pop(), taint_stack.pop()
-	if (tx|ty)&CALLDATA_FLAG > 0 {
-		if temp_y.Cmp(temp_x) > 0 {
-			if checkSubProtection(pc, contract) {
-				temp_flag |= PROTECTED_OVERFLOW_FLAG
-				global_taint_flag |= PROTECTED_OVERFLOW_FLAG
-			} else {
-				temp_flag |= OVERFLOW_FLAG
-				global_taint_flag |= OVERFLOW_FLAG
-			}
-		}
-		temp_flag |= POTENTIAL_OVERFLOW_FLAG
-		global_taint_flag |= POTENTIAL_OVERFLOW_FLAG
-	}
-
-	taint_stack.push(temp_flag)
-
-	evm.interpreter.taintIntPool.put(tx)
-
 	return nil, nil, nil
 }
 
-func opMul(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opMul(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	x, y := stack.pop(), stack.pop()
-	temp_x := new(big.Int).Set(x)
-	temp_y := new(big.Int).Set(y)
 	stack.push(math.U256(x.Mul(x, y)))
 
 	evm.interpreter.intPool.put(y)
-
-	temp_res := new(big.Int).Set(x)
-	temp_flag := SAFE_FLAG
-
-	tx, ty := taint_stack.pop(), taint_stack.pop()
-	if (tx|ty)&CALLDATA_FLAG > 0 {
-		if temp_x.Cmp(big.NewInt(0)) != 0 && math.U256(temp_res.Div(temp_res, temp_x)).Cmp(temp_y) != 0 {
-			if checkMulProtection(pc, contract) {
-				temp_flag |= PROTECTED_OVERFLOW_FLAG
-				global_taint_flag |= PROTECTED_OVERFLOW_FLAG
-			} else {
-				temp_flag |= OVERFLOW_FLAG
-				global_taint_flag |= OVERFLOW_FLAG
-			}
-		}
-		temp_flag |= POTENTIAL_OVERFLOW_FLAG
-		global_taint_flag |= POTENTIAL_OVERFLOW_FLAG
-	}
-
-	taint_stack.push(temp_flag)
-
-	evm.interpreter.taintIntPool.put(ty)
-
 	return nil, nil, nil
 }
 
-func opDiv(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opDiv(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	x, y := stack.pop(), stack.peek()
 	if y.Sign() != 0 {
 		math.U256(y.Div(x, y))
@@ -326,15 +92,10 @@ func opDiv(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stac
 		y.SetUint64(0)
 	}
 	evm.interpreter.intPool.put(x)
-
-	// solidity div cannot overflow
-	tx, ty := taint_stack.pop(), taint_stack.pop()
-	taint_stack.push(tx | ty)
-	evm.interpreter.taintIntPool.put(tx)
 	return nil, nil, nil
 }
 
-func opSdiv(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opSdiv(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	x, y := math.S256(stack.pop()), math.S256(stack.pop())
 	res := evm.interpreter.intPool.getZero()
 
@@ -350,15 +111,10 @@ func opSdiv(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Sta
 		stack.push(math.U256(res))
 	}
 	evm.interpreter.intPool.put(x, y)
-
-	tx, ty := taint_stack.pop(), taint_stack.pop()
-	evm.interpreter.taintIntPool.getZero()
-	taint_stack.push(tx | ty)
-	evm.interpreter.taintIntPool.put(tx, ty)
 	return nil, nil, nil
 }
 
-func opMod(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opMod(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	x, y := stack.pop(), stack.pop()
 	if y.Sign() == 0 {
 		stack.push(x.SetUint64(0))
@@ -366,14 +122,10 @@ func opMod(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stac
 		stack.push(math.U256(x.Mod(x, y)))
 	}
 	evm.interpreter.intPool.put(y)
-
-	tx, ty := taint_stack.pop(), taint_stack.pop()
-	taint_stack.push(tx | ty)
-	evm.interpreter.taintIntPool.put(ty)
 	return nil, nil, nil
 }
 
-func opSmod(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opSmod(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	x, y := math.S256(stack.pop()), math.S256(stack.pop())
 	res := evm.interpreter.intPool.getZero()
 
@@ -389,44 +141,19 @@ func opSmod(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Sta
 		stack.push(math.U256(res))
 	}
 	evm.interpreter.intPool.put(x, y)
-
-	tx, ty := taint_stack.pop(), taint_stack.pop()
-	evm.interpreter.taintIntPool.getZero()
-	taint_stack.push(tx | ty)
-	evm.interpreter.taintIntPool.put(tx, ty)
 	return nil, nil, nil
 }
 
-func opExp(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opExp(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	base, exponent := stack.pop(), stack.pop()
-	temp_x := new(big.Int).Set(base)
-	temp_y := new(big.Int).Set(exponent)
 	stack.push(math.Exp(base, exponent))
 
 	evm.interpreter.intPool.put(base, exponent)
-
-	//temp_res := new(big.Int).Set(math.Exp(temp_x, temp_y))
-	temp_flag := SAFE_FLAG
-
-	tx, ty := taint_stack.pop(), taint_stack.pop()
-	if (tx|ty)&CALLDATA_FLAG > 0 {
-		if checkExpOverflow(temp_x, temp_y) {
-			temp_flag |= OVERFLOW_FLAG
-			global_taint_flag |= OVERFLOW_FLAG
-		}
-		temp_flag |= POTENTIAL_OVERFLOW_FLAG
-		global_taint_flag |= POTENTIAL_OVERFLOW_FLAG
-	}
-
-	taint_stack.push(temp_flag)
-
-	evm.interpreter.taintIntPool.put(tx, ty)
 	return nil, nil, nil
 }
 
-func opSignExtend(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opSignExtend(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	back := stack.pop()
-	tx := taint_stack.pop()
 	if back.Cmp(big.NewInt(31)) < 0 {
 		bit := uint(back.Uint64()*8 + 7)
 		num := stack.pop()
@@ -438,26 +165,19 @@ func opSignExtend(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stac
 			num.And(num, mask)
 		}
 		stack.push(math.U256(num))
-
-		ty := taint_stack.pop()
-		taint_stack.push(tx | ty)
 	}
 
 	evm.interpreter.intPool.put(back)
-
-	evm.interpreter.taintIntPool.put(tx)
 	return nil, nil, nil
 }
 
-func opNot(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opNot(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	x := stack.peek()
 	math.U256(x.Not(x))
-
-	// Nothing to do
 	return nil, nil, nil
 }
 
-func opLt(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opLt(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	x, y := stack.pop(), stack.peek()
 	if x.Cmp(y) < 0 {
 		y.SetUint64(1)
@@ -465,14 +185,10 @@ func opLt(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack
 		y.SetUint64(0)
 	}
 	evm.interpreter.intPool.put(x)
-
-	tx, ty := taint_stack.pop(), taint_stack.pop()
-	taint_stack.push(tx | ty)
-	evm.interpreter.taintIntPool.put(tx)
 	return nil, nil, nil
 }
 
-func opGt(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opGt(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	x, y := stack.pop(), stack.peek()
 	if x.Cmp(y) > 0 {
 		y.SetUint64(1)
@@ -480,14 +196,10 @@ func opGt(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack
 		y.SetUint64(0)
 	}
 	evm.interpreter.intPool.put(x)
-
-	tx, ty := taint_stack.pop(), taint_stack.pop()
-	taint_stack.push(tx | ty)
-	evm.interpreter.taintIntPool.put(tx)
 	return nil, nil, nil
 }
 
-func opSlt(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opSlt(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	x, y := stack.pop(), stack.peek()
 
 	xSign := x.Cmp(tt255)
@@ -508,14 +220,10 @@ func opSlt(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stac
 		}
 	}
 	evm.interpreter.intPool.put(x)
-
-	tx, ty := taint_stack.pop(), taint_stack.pop()
-	taint_stack.push(tx | ty)
-	evm.interpreter.taintIntPool.put(tx)
 	return nil, nil, nil
 }
 
-func opSgt(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opSgt(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	x, y := stack.pop(), stack.peek()
 
 	xSign := x.Cmp(tt255)
@@ -536,14 +244,10 @@ func opSgt(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stac
 		}
 	}
 	evm.interpreter.intPool.put(x)
-
-	tx, ty := taint_stack.pop(), taint_stack.pop()
-	taint_stack.push(tx | ty)
-	evm.interpreter.taintIntPool.put(tx)
 	return nil, nil, nil
 }
 
-func opEq(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opEq(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	x, y := stack.pop(), stack.peek()
 	if x.Cmp(y) == 0 {
 		y.SetUint64(1)
@@ -551,62 +255,44 @@ func opEq(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack
 		y.SetUint64(0)
 	}
 	evm.interpreter.intPool.put(x)
-
-	tx, ty := taint_stack.pop(), taint_stack.pop()
-	taint_stack.push(tx | ty)
-	evm.interpreter.taintIntPool.put(tx)
 	return nil, nil, nil
 }
 
-func opIszero(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opIszero(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	x := stack.peek()
 	if x.Sign() > 0 {
 		x.SetUint64(0)
 	} else {
 		x.SetUint64(1)
 	}
-
-	// taint_stack: nothing to do
 	return nil, nil, nil
 }
 
-func opAnd(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opAnd(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	x, y := stack.pop(), stack.pop()
 	stack.push(x.And(x, y))
 
 	evm.interpreter.intPool.put(y)
-
-	tx, ty := taint_stack.pop(), taint_stack.pop()
-	taint_stack.push(tx | ty)
-	evm.interpreter.taintIntPool.put(ty)
 	return nil, nil, nil
 }
 
-func opOr(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opOr(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	x, y := stack.pop(), stack.peek()
 	y.Or(x, y)
 
 	evm.interpreter.intPool.put(x)
-
-	tx, ty := taint_stack.pop(), taint_stack.pop()
-	taint_stack.push(tx | ty)
-	evm.interpreter.taintIntPool.put(tx)
 	return nil, nil, nil
 }
 
-func opXor(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opXor(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	x, y := stack.pop(), stack.peek()
 	y.Xor(x, y)
 
 	evm.interpreter.intPool.put(x)
-
-	tx, ty := taint_stack.pop(), taint_stack.pop()
-	taint_stack.push(tx | ty)
-	evm.interpreter.taintIntPool.put(tx)
 	return nil, nil, nil
 }
 
-func opByte(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opByte(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	th, val := stack.pop(), stack.peek()
 	if th.Cmp(common.Big32) < 0 {
 		b := math.Byte(val, 32, int(th.Int64()))
@@ -615,149 +301,77 @@ func opByte(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Sta
 		val.SetUint64(0)
 	}
 	evm.interpreter.intPool.put(th)
-
-	tx, ty := taint_stack.pop(), taint_stack.pop()
-	taint_stack.push(tx | ty)
-	evm.interpreter.taintIntPool.put(tx)
 	return nil, nil, nil
 }
 
-func opAddmod(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opAddmod(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	x, y, z := stack.pop(), stack.pop(), stack.pop()
-	tx, ty, tz := taint_stack.pop(), taint_stack.pop(), taint_stack.pop()
-	temp_x := new(big.Int).Set(x)
-	temp_y := new(big.Int).Set(y)
 	if z.Cmp(bigZero) > 0 {
 		x.Add(x, y)
-
-		temp_flag := SAFE_FLAG
-		temp_res := new(big.Int).Set(x)
-
 		x.Mod(x, z)
 		stack.push(math.U256(x))
-
-		if (tx|ty)&CALLDATA_FLAG > 0 {
-			if temp_res.Cmp(temp_x) < 0 || temp_res.Cmp(temp_y) < 0 {
-				if checkAddProtection(pc, contract) {
-					temp_flag |= PROTECTED_OVERFLOW_FLAG
-					global_taint_flag |= PROTECTED_OVERFLOW_FLAG
-				} else {
-					temp_flag |= OVERFLOW_FLAG
-					global_taint_flag |= OVERFLOW_FLAG
-				}
-			}
-			temp_flag |= POTENTIAL_OVERFLOW_FLAG
-			global_taint_flag |= POTENTIAL_OVERFLOW_FLAG
-		}
-
-		taint_stack.push(temp_flag)
-
 	} else {
 		stack.push(x.SetUint64(0))
-
-		taint_stack.push(tz)
 	}
 	evm.interpreter.intPool.put(y, z)
-
-	evm.interpreter.taintIntPool.put(ty, tz)
 	return nil, nil, nil
 }
 
-func opMulmod(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opMulmod(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	x, y, z := stack.pop(), stack.pop(), stack.pop()
-	tx, ty, tz := taint_stack.pop(), taint_stack.pop(), taint_stack.pop()
-	temp_x := new(big.Int).Set(x)
-	temp_y := new(big.Int).Set(y)
 	if z.Cmp(bigZero) > 0 {
 		x.Mul(x, y)
-
-		temp_flag := SAFE_FLAG
-		temp_res := new(big.Int).Set(x)
-
 		x.Mod(x, z)
 		stack.push(math.U256(x))
-
-		if (tx|ty)&CALLDATA_FLAG > 0 {
-			if temp_x.Cmp(big.NewInt(0)) != 0 && math.U256(temp_res.Div(temp_res, temp_x)).Cmp(temp_y) != 0 {
-				if checkMulProtection(pc, contract) {
-					temp_flag |= PROTECTED_OVERFLOW_FLAG
-					global_taint_flag |= PROTECTED_OVERFLOW_FLAG
-				} else {
-					temp_flag |= OVERFLOW_FLAG
-					global_taint_flag |= OVERFLOW_FLAG
-				}
-			}
-			temp_flag |= POTENTIAL_OVERFLOW_FLAG
-			global_taint_flag |= POTENTIAL_OVERFLOW_FLAG
-		}
-		taint_stack.push(temp_flag)
 	} else {
 		stack.push(x.SetUint64(0))
-
-		taint_stack.push(tz)
 	}
 	evm.interpreter.intPool.put(y, z)
-
-	evm.interpreter.taintIntPool.put(ty, tz)
 	return nil, nil, nil
 }
 
 // opSHL implements Shift Left
 // The SHL instruction (shift left) pops 2 values from the stack, first arg1 and then arg2,
 // and pushes on the stack arg2 shifted to the left by arg1 number of bits.
-func opSHL(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opSHL(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	// Note, second operand is left in the stack; accumulate result into it, and no need to push it afterwards
 	shift, value := math.U256(stack.pop()), math.U256(stack.peek())
 	defer evm.interpreter.intPool.put(shift) // First operand back into the pool
 
-	tx, ty := taint_stack.pop(), taint_stack.pop()
-	taint_stack.push(tx | ty)
-	evm.interpreter.taintIntPool.put(tx)
-
 	if shift.Cmp(common.Big256) >= 0 {
 		value.SetUint64(0)
 		return nil, nil, nil
 	}
 	n := uint(shift.Uint64())
 	math.U256(value.Lsh(value, n))
-
 	return nil, nil, nil
 }
 
 // opSHR implements Logical Shift Right
 // The SHR instruction (logical shift right) pops 2 values from the stack, first arg1 and then arg2,
 // and pushes on the stack arg2 shifted to the right by arg1 number of bits with zero fill.
-func opSHR(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opSHR(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	// Note, second operand is left in the stack; accumulate result into it, and no need to push it afterwards
 	shift, value := math.U256(stack.pop()), math.U256(stack.peek())
 	defer evm.interpreter.intPool.put(shift) // First operand back into the pool
 
-	tx, ty := taint_stack.pop(), taint_stack.pop()
-	taint_stack.push(tx | ty)
-	evm.interpreter.taintIntPool.put(tx)
-
 	if shift.Cmp(common.Big256) >= 0 {
 		value.SetUint64(0)
 		return nil, nil, nil
 	}
 	n := uint(shift.Uint64())
 	math.U256(value.Rsh(value, n))
-
 	return nil, nil, nil
 }
 
 // opSAR implements Arithmetic Shift Right
 // The SAR instruction (arithmetic shift right) pops 2 values from the stack, first arg1 and then arg2,
 // and pushes on the stack arg2 shifted to the right by arg1 number of bits with sign extension.
-func opSAR(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opSAR(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	// Note, S256 returns (potentially) a new bigint, so we're popping, not peeking this one
 	shift, value := math.U256(stack.pop()), math.S256(stack.pop())
 	defer evm.interpreter.intPool.put(shift) // First operand back into the pool
 
-	tx, ty := taint_stack.pop(), taint_stack.pop()
-	taint_stack.push(tx | ty)
-	evm.interpreter.taintIntPool.put(tx)
-
 	if shift.Cmp(common.Big256) >= 0 {
 		if value.Sign() > 0 {
 			value.SetUint64(0)
@@ -770,11 +384,10 @@ func opSAR(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stac
 	n := uint(shift.Uint64())
 	value.Rsh(value, n)
 	stack.push(math.U256(value))
-
 	return nil, nil, nil
 }
 
-func opSha3(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opSha3(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	offset, size := stack.pop(), stack.pop()
 	data := memory.Get(offset.Int64(), size.Int64())
 	hash := crypto.Keccak256(data)
@@ -785,80 +398,47 @@ func opSha3(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Sta
 	stack.push(evm.interpreter.intPool.get().SetBytes(hash))
 
 	evm.interpreter.intPool.put(offset, size)
-
-	tx, ty := taint_stack.pop(), taint_stack.pop()
-	t_data := taint_memory.Get(offset.Int64(), size.Int64())
-	flag := SAFE_FLAG
-	for i := int64(0); i < size.Int64(); i++ {
-		flag = flag | t_data[i]
-	}
-	evm.interpreter.taintIntPool.get()
-	taint_stack.push(flag)
-	evm.interpreter.taintIntPool.put(tx, ty)
 	return nil, nil, nil
 }
 
-func opAddress(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opAddress(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	stack.push(contract.Address().Big())
-
-	taint_stack.push(SAFE_FLAG)
 	return nil, nil, nil
 }
 
-func opBalance(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opBalance(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	slot := stack.peek()
 	slot.Set(evm.StateDB.GetBalance(common.BigToAddress(slot)))
-
-	tx := taint_stack.pop()
-	taint_stack.push(tx)
 	return nil, nil, nil
 }
 
-func opOrigin(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opOrigin(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	stack.push(evm.Origin.Big())
-
-	taint_stack.push(SAFE_FLAG)
 	return nil, nil, nil
 }
 
-func opCaller(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opCaller(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	stack.push(contract.Caller().Big())
-
-	taint_stack.push(SAFE_FLAG)
 	return nil, nil, nil
 }
 
-func opCallValue(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opCallValue(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	stack.push(evm.interpreter.intPool.get().Set(contract.value))
-
-	evm.interpreter.taintIntPool.get()
-	taint_stack.push(CALLDATA_FLAG)
 	return nil, nil, nil
 }
 
-func opCallDataLoad(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
-	stack.push(evm.interpreter.intPool.get().SetBytes(getDataBig(contract.Input, stack.pop(), big32)))
-
-	evm.interpreter.taintIntPool.get()
-	taint_stack.pop()
-	if *pc == 14 {
-		// func selection
-		taint_stack.push(SAFE_FLAG)
-	} else {
-		taint_stack.push(CALLDATA_FLAG)
-	}
+func opCallDataLoad(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
+	offset := stack.pop()
+	stack.push(evm.interpreter.intPool.get().SetBytes(getDataBig(contract.Input, offset, big32)))
 	return nil, nil, nil
 }
 
-func opCallDataSize(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opCallDataSize(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	stack.push(evm.interpreter.intPool.get().SetInt64(int64(len(contract.Input))))
-
-	evm.interpreter.taintIntPool.get()
-	taint_stack.push(SAFE_FLAG)
 	return nil, nil, nil
 }
 
-func opCallDataCopy(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opCallDataCopy(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	var (
 		memOffset  = stack.pop()
 		dataOffset = stack.pop()
@@ -867,27 +447,15 @@ func opCallDataCopy(pc *uint64, evm *EVM, contract *Contract, memory *Memory, st
 	memory.Set(memOffset.Uint64(), length.Uint64(), getDataBig(contract.Input, dataOffset, length))
 
 	evm.interpreter.intPool.put(memOffset, dataOffset, length)
-
-	tx, ty, tz := taint_stack.pop(), taint_stack.pop(), taint_stack.pop()
-	var t_value []int
-	for i := int64(0); i < length.Int64(); i++ {
-		t_value = append(t_value, CALLDATA_FLAG)
-	}
-	taint_memory.Set(memOffset.Uint64(), length.Uint64(), t_value)
-
-	evm.interpreter.taintIntPool.put(tx, ty, tz)
 	return nil, nil, nil
 }
 
-func opReturnDataSize(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opReturnDataSize(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	stack.push(evm.interpreter.intPool.get().SetUint64(uint64(len(evm.interpreter.returnData))))
-
-	evm.interpreter.taintIntPool.get()
-	taint_stack.push(SAFE_FLAG)
 	return nil, nil, nil
 }
 
-func opReturnDataCopy(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opReturnDataCopy(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	var (
 		memOffset  = stack.pop()
 		dataOffset = stack.pop()
@@ -897,39 +465,37 @@ func opReturnDataCopy(pc *uint64, evm *EVM, contract *Contract, memory *Memory,
 	)
 	defer evm.interpreter.intPool.put(memOffset, dataOffset, length, end)
 
-	tm, td, tl := taint_stack.pop(), taint_stack.pop(), taint_stack.pop()
-	evm.interpreter.taintIntPool.get()
-	te := td | tl
-	evm.interpreter.taintIntPool.put(tm, td, tl, te)
-
 	if end.BitLen() > 64 || uint64(len(evm.interpreter.returnData)) < end.Uint64() {
 		return nil, nil, errReturnDataOutOfBounds
 	}
 	memory.Set(memOffset.Uint64(), length.Uint64(), evm.interpreter.returnData[dataOffset.Uint64():end.Uint64()])
-
-	taint_memory.Set(memOffset.Uint64(), length.Uint64(), evm.interpreter.returnFlag[dataOffset.Uint64():end.Uint64()])
 	return nil, nil, nil
 }
 
-func opExtCodeSize(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opExtCodeSize(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	slot := stack.peek()
 	slot.SetUint64(uint64(evm.StateDB.GetCodeSize(common.BigToAddress(slot))))
+	return nil, nil, nil
+}
 
-	taint_stack.pop()
-	taint_stack.push(SAFE_FLAG)
+func opExtCodeHash(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
+	slot := stack.peek()
+	address := common.BigToAddress(slot)
+	if evm.StateDB.Empty(address) {
+		slot.SetUint64(0)
+	} else {
+		slot.SetBytes(evm.StateDB.GetCodeHash(address).Bytes())
+	}
 	return nil, nil, nil
 }
 
-func opCodeSize(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opCodeSize(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	l := evm.interpreter.intPool.get().SetInt64(int64(len(contract.Code)))
 	stack.push(l)
-
-	evm.interpreter.taintIntPool.get()
-	taint_stack.push(SAFE_FLAG)
 	return nil, nil, nil
 }
 
-func opCodeCopy(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opCodeCopy(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	var (
 		memOffset  = stack.pop()
 		codeOffset = stack.pop()
@@ -939,19 +505,10 @@ func opCodeCopy(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack
 	memory.Set(memOffset.Uint64(), length.Uint64(), codeCopy)
 
 	evm.interpreter.intPool.put(memOffset, codeOffset, length)
-
-	tm, tc, tl := taint_stack.pop(), taint_stack.pop(), taint_stack.pop()
-	var t_value []int
-	for i := uint64(0); i < length.Uint64(); i++ {
-		t_value = append(t_value, SAFE_FLAG)
-	}
-	taint_memory.Set(memOffset.Uint64(), length.Uint64(), t_value)
-
-	evm.interpreter.taintIntPool.put(tm, tc, tl)
 	return nil, nil, nil
 }
 
-func opExtCodeCopy(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opExtCodeCopy(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	var (
 		addr       = common.BigToAddress(stack.pop())
 		memOffset  = stack.pop()
@@ -962,167 +519,99 @@ func opExtCodeCopy(pc *uint64, evm *EVM, contract *Contract, memory *Memory, sta
 	memory.Set(memOffset.Uint64(), length.Uint64(), codeCopy)
 
 	evm.interpreter.intPool.put(memOffset, codeOffset, length)
-
-	_, tm, tc, tl := taint_stack.pop(), taint_stack.pop(), taint_stack.pop(), taint_stack.pop()
-	var t_value []int
-	for i := int64(0); i < length.Int64(); i++ {
-		t_value = append(t_value, SAFE_FLAG)
-	}
-	taint_memory.Set(memOffset.Uint64(), length.Uint64(), t_value)
-	evm.interpreter.taintIntPool.put(tm, tc, tl)
 	return nil, nil, nil
 }
 
-func opGasprice(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opGasprice(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	stack.push(evm.interpreter.intPool.get().Set(evm.GasPrice))
-
-	evm.interpreter.taintIntPool.get()
-	taint_stack.push(SAFE_FLAG)
 	return nil, nil, nil
 }
 
-func opBlockhash(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opBlockhash(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	num := stack.pop()
 
 	n := evm.interpreter.intPool.get().Sub(evm.BlockNumber, common.Big257)
-
-	t_num := taint_stack.pop()
-	evm.interpreter.taintIntPool.get()
-
 	if num.Cmp(n) > 0 && num.Cmp(evm.BlockNumber) < 0 {
 		stack.push(evm.GetHash(num.Uint64()).Big())
-
-		taint_stack.push(t_num)
 	} else {
 		stack.push(evm.interpreter.intPool.getZero())
-
-		taint_stack.push(evm.interpreter.taintIntPool.getZero())
 	}
 	evm.interpreter.intPool.put(num, n)
-
-	evm.interpreter.taintIntPool.put(t_num, SAFE_FLAG)
 	return nil, nil, nil
 }
 
-func opCoinbase(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opCoinbase(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	stack.push(evm.Coinbase.Big())
-
-	taint_stack.push(SAFE_FLAG)
 	return nil, nil, nil
 }
 
-func opTimestamp(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opTimestamp(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	stack.push(math.U256(evm.interpreter.intPool.get().Set(evm.Time)))
-
-	evm.interpreter.taintIntPool.get()
-	taint_stack.push(SAFE_FLAG)
 	return nil, nil, nil
 }
 
-func opNumber(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opNumber(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	stack.push(math.U256(evm.interpreter.intPool.get().Set(evm.BlockNumber)))
-
-	evm.interpreter.taintIntPool.get()
-	taint_stack.push(SAFE_FLAG)
 	return nil, nil, nil
 }
 
-func opDifficulty(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opDifficulty(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	stack.push(math.U256(evm.interpreter.intPool.get().Set(evm.Difficulty)))
-
-	evm.interpreter.taintIntPool.get()
-	taint_stack.push(SAFE_FLAG)
 	return nil, nil, nil
 }
 
-func opGasLimit(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opGasLimit(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	stack.push(math.U256(evm.interpreter.intPool.get().SetUint64(evm.GasLimit)))
-
-	evm.interpreter.taintIntPool.get()
-	taint_stack.push(SAFE_FLAG)
 	return nil, nil, nil
 }
 
-func opPop(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opPop(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	evm.interpreter.intPool.put(stack.pop())
-
-	evm.interpreter.taintIntPool.put(taint_stack.pop())
 	return nil, nil, nil
 }
 
-func opMload(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opMload(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	offset := stack.pop()
 	val := evm.interpreter.intPool.get().SetBytes(memory.Get(offset.Int64(), 32))
 	stack.push(val)
 
 	evm.interpreter.intPool.put(offset)
-
-	toffset := taint_stack.pop()
-	taint_val := taint_memory.Get(offset.Int64(), 32)
-	flag := SAFE_FLAG
-	for i := 0; i < 32; i++ {
-		flag = flag | taint_val[i]
-	}
-	taint_stack.push(flag)
-	evm.interpreter.taintIntPool.put(toffset)
 	return nil, nil, nil
 }
 
-func opMstore(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opMstore(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	// pop value of the stack
 	mStart, val := stack.pop(), stack.pop()
 	memory.Set(mStart.Uint64(), 32, math.PaddedBigBytes(val, 32))
 
 	evm.interpreter.intPool.put(mStart, val)
-
-	tx, ty := taint_stack.pop(), taint_stack.pop()
-	slice_ty := make([]int, 32)
-	for i := 0; i < 32; i++ {
-		slice_ty[i] = ty
-	}
-	//taint_memory.Resize(mStart.Uint64() + 32)
-	taint_memory.Set(mStart.Uint64(), 32, slice_ty)
-	evm.interpreter.taintIntPool.put(tx, ty)
 	return nil, nil, nil
 }
 
-func opMstore8(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opMstore8(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	off, val := stack.pop().Int64(), stack.pop().Int64()
 	memory.store[off] = byte(val & 0xff)
-
-	_, tv := taint_stack.pop(), taint_stack.pop()
-	taint_memory.store[off] = tv
 	return nil, nil, nil
 }
 
-func opSload(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opSload(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	loc := common.BigToHash(stack.pop())
 	val := evm.StateDB.GetState(contract.Address(), loc).Big()
 	stack.push(val)
-
-	tl := taint_stack.pop()
-	tv := tl
-	taint_stack.push(tv)
 	return nil, nil, nil
 }
 
-func opSstore(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opSstore(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	loc := common.BigToHash(stack.pop())
 	val := stack.pop()
 	evm.StateDB.SetState(contract.Address(), loc, common.BigToHash(val))
 
 	evm.interpreter.intPool.put(val)
-
-	taint_stack.pop()
-	tv := taint_stack.pop()
-	evm.interpreter.taintIntPool.put(tv)
 	return nil, nil, nil
 }
 
-func opJump(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opJump(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	pos := stack.pop()
-	tx := taint_stack.pop()
-
 	if !contract.jumpdests.has(contract.CodeHash, contract.Code, pos) {
 		nop := contract.GetOp(pos.Uint64())
 		return nil, nil, fmt.Errorf("invalid jump destination (%v) %v", nop, pos)
@@ -1130,15 +619,11 @@ func opJump(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Sta
 	*pc = pos.Uint64()
 
 	evm.interpreter.intPool.put(pos)
-
-	evm.interpreter.taintIntPool.put(tx)
 	return nil, nil, nil
 }
 
-func opJumpi(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opJumpi(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	pos, cond := stack.pop(), stack.pop()
-	tx, ty := taint_stack.pop(), taint_stack.pop()
-
 	if cond.Sign() != 0 {
 		if !contract.jumpdests.has(contract.CodeHash, contract.Code, pos) {
 			nop := contract.GetOp(pos.Uint64())
@@ -1150,51 +635,35 @@ func opJumpi(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *St
 	}
 
 	evm.interpreter.intPool.put(pos, cond)
-
-	evm.interpreter.taintIntPool.put(tx, ty)
 	return nil, nil, nil
 }
 
-func opJumpdest(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opJumpdest(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	return nil, nil, nil
 }
 
-func opPc(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opPc(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	stack.push(evm.interpreter.intPool.get().SetUint64(*pc))
-
-	evm.interpreter.taintIntPool.get()
-	taint_stack.push(SAFE_FLAG)
 	return nil, nil, nil
 }
 
-func opMsize(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opMsize(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	stack.push(evm.interpreter.intPool.get().SetInt64(int64(memory.Len())))
-
-	evm.interpreter.taintIntPool.get()
-	taint_stack.push(SAFE_FLAG)
 	return nil, nil, nil
 }
 
-func opGas(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opGas(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	stack.push(evm.interpreter.intPool.get().SetUint64(contract.Gas))
-
-	evm.interpreter.taintIntPool.get()
-	taint_stack.push(SAFE_FLAG)
 	return nil, nil, nil
 }
 
-func opCreate(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opCreate(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	var (
 		value        = stack.pop()
 		offset, size = stack.pop(), stack.pop()
 		input        = memory.Get(offset.Int64(), size.Int64())
 		gas          = contract.Gas
 	)
-
-	tv := taint_stack.pop()
-	to, ts := taint_stack.pop(), taint_stack.pop()
-	taint_memory.Get(offset.Int64(), size.Int64())
-
 	if evm.ChainConfig().IsEIP150(evm.BlockNumber) {
 		gas -= gas / 64
 	}
@@ -1207,21 +676,42 @@ func opCreate(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *S
 	// ignore this error and pretend the operation was successful.
 	if evm.ChainConfig().IsHomestead(evm.BlockNumber) && suberr == ErrCodeStoreOutOfGas {
 		stack.push(evm.interpreter.intPool.getZero())
-
-		taint_stack.push(evm.interpreter.taintIntPool.getZero())
 	} else if suberr != nil && suberr != ErrCodeStoreOutOfGas {
 		stack.push(evm.interpreter.intPool.getZero())
-
-		taint_stack.push(evm.interpreter.taintIntPool.getZero())
 	} else {
 		stack.push(addr.Big())
-
-		taint_stack.push(SAFE_FLAG)
 	}
 	contract.Gas += returnGas
 	evm.interpreter.intPool.put(value, offset, size)
 
-	evm.interpreter.taintIntPool.put(tv, to, ts)
+	if suberr == errExecutionReverted {
+		return res, returnFlag, nil
+	}
+	return nil, nil, nil
+}
+
+func opCreate2(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
+	var (
+		value        = stack.pop()
+		offset, size = stack.pop(), stack.pop()
+		salt         = stack.pop()
+		input        = memory.Get(offset.Int64(), size.Int64())
+		gas          = contract.Gas
+	)
+	// Apply EIP150
+	if evm.ChainConfig().IsEIP150(evm.BlockNumber) {
+		gas -= gas / 64
+	}
+	contract.UseGas(gas)
+	res, returnFlag, addr, returnGas, suberr := evm.Create2(contract, input, gas, value, salt)
+	// Push item on the stack based on the returned error.
+	if suberr != nil {
+		stack.push(evm.interpreter.intPool.getZero())
+	} else {
+		stack.push(addr.Big())
+	}
+	contract.Gas += returnGas
+	evm.interpreter.intPool.put(value, offset, size, salt)
 
 	if suberr == errExecutionReverted {
 		return res, returnFlag, nil
@@ -1229,208 +719,146 @@ func opCreate(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *S
 	return nil, nil, nil
 }
 
-func opCall(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opCall(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	// Pop gas. The actual gas in in evm.callGasTemp.
 	evm.interpreter.intPool.put(stack.pop())
-	evm.interpreter.taintIntPool.put(taint_stack.pop())
 
 	gas := evm.callGasTemp
 	// Pop other call parameters.
 	addr, value, inOffset, inSize, retOffset, retSize := stack.pop(), stack.pop(), stack.pop(), stack.pop(), stack.pop(), stack.pop()
-	t1, t2, t3, t4, t5, t6 := taint_stack.pop(), taint_stack.pop(), taint_stack.pop(), taint_stack.pop(), taint_stack.pop(), taint_stack.pop()
 
 	toAddr := common.BigToAddress(addr)
 	value = math.U256(value)
 	// Get the arguments from the memory.
 	args := memory.Get(inOffset.Int64(), inSize.Int64())
-	taint_memory.Get(inOffset.Int64(), inSize.Int64())
 
 	if value.Sign() != 0 {
 		gas += params.CallStipend
 	}
 
-	ret, returnFlag, returnGas, err := evm.Call(contract, toAddr, args, gas, value)
+	ret, returnFlag, returnGas, err := evm.Call(contract, toAddr, args, evm.interpreter.callTaint, gas, value)
 	if err != nil {
 		stack.push(evm.interpreter.intPool.getZero())
-		taint_stack.push(evm.interpreter.taintIntPool.getZero())
 	} else {
 		stack.push(evm.interpreter.intPool.get().SetUint64(1))
-		evm.interpreter.taintIntPool.get()
-		taint_stack.push(SAFE_FLAG)
 	}
 	if err == nil || err == errExecutionReverted {
 		memory.Set(retOffset.Uint64(), retSize.Uint64(), ret)
-		taint_memory.Set(retOffset.Uint64(), retSize.Uint64(), returnFlag)
 	}
 	contract.Gas += returnGas
 
 	evm.interpreter.intPool.put(addr, value, inOffset, inSize, retOffset, retSize)
-	evm.interpreter.taintIntPool.put(t1, t2, t3, t4, t5, t6)
-
 	return ret, returnFlag, nil
 }
 
-func opCallCode(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opCallCode(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	// Pop gas. The actual gas is in evm.callGasTemp.
 	evm.interpreter.intPool.put(stack.pop())
 
-	evm.interpreter.taintIntPool.put(taint_stack.pop())
-
 	gas := evm.callGasTemp
 	// Pop other call parameters.
 	addr, value, inOffset, inSize, retOffset, retSize := stack.pop(), stack.pop(), stack.pop(), stack.pop(), stack.pop(), stack.pop()
 
-	t1, t2, t3, t4, t5, t6 := taint_stack.pop(), taint_stack.pop(), taint_stack.pop(), taint_stack.pop(), taint_stack.pop(), taint_stack.pop()
-
 	toAddr := common.BigToAddress(addr)
 	value = math.U256(value)
 	// Get arguments from the memory.
 	args := memory.Get(inOffset.Int64(), inSize.Int64())
-	taint_memory.Get(inOffset.Int64(), inSize.Int64())
 
 	if value.Sign() != 0 {
 		gas += params.CallStipend
 	}
 
-	ret, returnFlag, returnGas, err := evm.CallCode(contract, toAddr, args, gas, value)
+	ret, returnFlag, returnGas, err := evm.CallCode(contract, toAddr, args, evm.interpreter.callTaint, gas, value)
 	if err != nil {
 		stack.push(evm.interpreter.intPool.getZero())
-		taint_stack.push(evm.interpreter.taintIntPool.getZero())
 	} else {
 		stack.push(evm.interpreter.intPool.get().SetUint64(1))
-		evm.interpreter.taintIntPool.get()
-		taint_stack.push(SAFE_FLAG)
 	}
 	if err == nil || err == errExecutionReverted {
 		memory.Set(retOffset.Uint64(), retSize.Uint64(), ret)
-		taint_memory.Set(retOffset.Uint64(), retSize.Uint64(), returnFlag)
 	}
 	contract.Gas += returnGas
 
 	evm.interpreter.intPool.put(addr, value, inOffset, inSize, retOffset, retSize)
-	evm.interpreter.taintIntPool.put(t1, t2, t3, t4, t5, t6)
-
 	return ret, returnFlag, nil
 }
 
-func opDelegateCall(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opDelegateCall(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	// Pop gas. The actual gas is in evm.callGasTemp.
 	evm.interpreter.intPool.put(stack.pop())
-	evm.interpreter.taintIntPool.put(taint_stack.pop())
 
 	gas := evm.callGasTemp
 	// Pop other call parameters.
 	addr, inOffset, inSize, retOffset, retSize := stack.pop(), stack.pop(), stack.pop(), stack.pop(), stack.pop()
-	t1, t2, t3, t4, t5 := taint_stack.pop(), taint_stack.pop(), taint_stack.pop(), taint_stack.pop(), taint_stack.pop()
 
 	toAddr := common.BigToAddress(addr)
 	// Get arguments from the memory.
 	args := memory.Get(inOffset.Int64(), inSize.Int64())
-	taint_memory.Get(inOffset.Int64(), inSize.Int64())
 
-	ret, returnFlag, returnGas, err := evm.DelegateCall(contract, toAddr, args, gas)
+	ret, returnFlag, returnGas, err := evm.DelegateCall(contract, toAddr, args, evm.interpreter.callTaint, gas)
 	if err != nil {
 		stack.push(evm.interpreter.intPool.getZero())
-		taint_stack.push(evm.interpreter.taintIntPool.getZero())
 	} else {
 		stack.push(evm.interpreter.intPool.get().SetUint64(1))
-		evm.interpreter.taintIntPool.get()
-		taint_stack.push(SAFE_FLAG)
 	}
 	if err == nil || err == errExecutionReverted {
 		memory.Set(retOffset.Uint64(), retSize.Uint64(), ret)
-		taint_memory.Set(retOffset.Uint64(), retSize.Uint64(), returnFlag)
 	}
 	contract.Gas += returnGas
 
 	evm.interpreter.intPool.put(addr, inOffset, inSize, retOffset, retSize)
-	evm.interpreter.taintIntPool.put(t1, t2, t3, t4, t5)
-
 	return ret, returnFlag, nil
 }
 
-func opStaticCall(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opStaticCall(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	// Pop gas. The actual gas is in evm.callGasTemp.
 	evm.interpreter.intPool.put(stack.pop())
-	evm.interpreter.taintIntPool.put(taint_stack.pop())
 
 	gas := evm.callGasTemp
 	// Pop other call parameters.
 	addr, inOffset, inSize, retOffset, retSize := stack.pop(), stack.pop(), stack.pop(), stack.pop(), stack.pop()
-	t1, t2, t3, t4, t5 := taint_stack.pop(), taint_stack.pop(), taint_stack.pop(), taint_stack.pop(), taint_stack.pop()
 
 	toAddr := common.BigToAddress(addr)
 	// Get arguments from the memory.
 	args := memory.Get(inOffset.Int64(), inSize.Int64())
-	taint_memory.Get(inOffset.Int64(), inSize.Int64())
 
-	ret, returnFlag, returnGas, err := evm.StaticCall(contract, toAddr, args, gas)
+	ret, returnFlag, returnGas, err := evm.StaticCall(contract, toAddr, args, evm.interpreter.callTaint, gas)
 	if err != nil {
 		stack.push(evm.interpreter.intPool.getZero())
-		taint_stack.push(evm.interpreter.taintIntPool.getZero())
 	} else {
 		stack.push(evm.interpreter.intPool.get().SetUint64(1))
-		evm.interpreter.taintIntPool.get()
-		taint_stack.push(SAFE_FLAG)
 	}
 	if err == nil || err == errExecutionReverted {
 		memory.Set(retOffset.Uint64(), retSize.Uint64(), ret)
-		taint_memory.Set(retOffset.Uint64(), retSize.Uint64(), returnFlag)
 	}
 	contract.Gas += returnGas
 
 	evm.interpreter.intPool.put(addr, inOffset, inSize, retOffset, retSize)
-	evm.interpreter.taintIntPool.put(t1, t2, t3, t4, t5)
-
 	return ret, returnFlag, nil
 }
 
-func opReturn(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opReturn(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	offset, size := stack.pop(), stack.pop()
 	ret := memory.GetPtr(offset.Int64(), size.Int64())
 
 	evm.interpreter.intPool.put(offset, size)
-
-	tx, ty := taint_stack.pop(), taint_stack.pop()
-	taint_ret := taint_memory.GetPtr(offset.Int64(), size.Int64())
-	evm.interpreter.taintIntPool.put(tx, ty)
-
-	taint_flag := SAFE_FLAG
-	for i := int64(0); i < size.Int64(); i++ {
-		taint_flag = taint_flag | taint_ret[i]
-	}
-
-	global_taint_flag |= taint_flag
-
-	return ret, taint_ret, nil
+	return ret, nil, nil
 }
 
-func opRevert(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opRevert(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	offset, size := stack.pop(), stack.pop()
 	ret := memory.GetPtr(offset.Int64(), size.Int64())
 
 	evm.interpreter.intPool.put(offset, size)
-
-	tx, ty := taint_stack.pop(), taint_stack.pop()
-	taint_ret := taint_memory.GetPtr(offset.Int64(), size.Int64())
-	evm.interpreter.taintIntPool.put(tx, ty)
-
-	taint_flag := SAFE_FLAG
-	for i := int64(0); i < size.Int64(); i++ {
-		taint_flag = taint_flag | taint_ret[i]
-	}
-
-	global_taint_flag |= taint_flag
-
 	return ret, nil, nil
 }
 
-func opStop(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opStop(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	// fmt.Println("stop")
 	return nil, nil, nil
 }
 
-func opSuicide(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+func opSuicide(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 	balance := evm.StateDB.GetBalance(contract.Address())
 	evm.StateDB.AddBalance(common.BigToAddress(stack.pop()), balance)
 
@@ -1444,7 +872,7 @@ func opSuicide(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *
 
 // make log instruction function
 func makeLog(size int) executionFunc {
-	return func(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+	return func(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 		topics := make([]common.Hash, size)
 		mStart, mSize := stack.pop(), stack.pop()
 		for i := 0; i < size; i++ {
@@ -1462,21 +890,13 @@ func makeLog(size int) executionFunc {
 		})
 
 		evm.interpreter.intPool.put(mStart, mSize)
-
-		t_mStart, t_mSize := taint_stack.pop(), taint_stack.pop()
-		for i := 0; i < size; i++ {
-			taint_stack.pop()
-		}
-		memory.Get(mStart.Int64(), mSize.Int64())
-		evm.interpreter.taintIntPool.put(t_mStart, t_mSize)
-
 		return nil, nil, nil
 	}
 }
 
 // make push instruction function
 func makePush(size uint64, pushByteSize int) executionFunc {
-	return func(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+	return func(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 		codeLen := len(contract.Code)
 
 		startMin := codeLen
@@ -1493,21 +913,14 @@ func makePush(size uint64, pushByteSize int) executionFunc {
 		stack.push(integer.SetBytes(common.RightPadBytes(contract.Code[startMin:endMin], pushByteSize)))
 
 		*pc += size
-
-		evm.interpreter.taintIntPool.get()
-		taint_stack.push(SAFE_FLAG)
-
 		return nil, nil, nil
 	}
 }
 
 // make dup instruction function
 func makeDup(size int64) executionFunc {
-	return func(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+	return func(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 		stack.dup(evm.interpreter.intPool, int(size))
-
-		taint_stack.dup(evm.interpreter.taintIntPool, int(size))
-
 		return nil, nil, nil
 	}
 }
@@ -1516,11 +929,8 @@ func makeDup(size int64) executionFunc {
 func makeSwap(size int64) executionFunc {
 	// switch n + 1 otherwise n would be swapped with n
 	size += 1
-	return func(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack, taint_memory *TaintMemory, taint_stack *TaintStack) ([]byte, []int, error) {
+	return func(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, []int, error) {
 		stack.swap(int(size))
-
-		taint_stack.swap(int(size))
-
 		return nil, nil, nil
 	}
 }
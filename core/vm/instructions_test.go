@@ -30,7 +30,7 @@ type twoOperandTest struct {
 	expected string
 }
 
-func testTwoOperandOp(t *testing.T, tests []twoOperandTest, opFn func(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, error)) {
+func testTwoOperandOp(t *testing.T, tests []twoOperandTest, opFn executionFunc) {
 	var (
 		env   = NewEVM(Context{}, nil, params.TestChainConfig, Config{})
 		stack = newstack()
@@ -196,7 +196,34 @@ func TestSLT(t *testing.T) {
 	testTwoOperandOp(t, tests, opSlt)
 }
 
-func opBenchmark(bench *testing.B, op func(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, error), args ...string) {
+func TestExpOverflow(t *testing.T) {
+	tests := []struct {
+		base, exponent string
+		overflow       bool
+	}{
+		{"00", "00", false},
+		{"00", "ff", false},
+		{"01", "ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff", false},
+		{"ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff", "01", false},
+		{"02", "03", false},
+		{"0a", "12", false},
+		{"02", "ff", false},
+		{"02", "0100", true},
+		{"03", "a1", false},
+		{"03", "a2", true},
+		{"0100000000000000000000000000000000", "02", true},
+		{"ffffffffffffffffffffffffffffffff", "02", false},
+		{"0100000000000000000000000000000000", "ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff", true},
+	}
+	for i, test := range tests {
+		base, exponent := new(big.Int).SetBytes(common.Hex2Bytes(test.base)), new(big.Int).SetBytes(common.Hex2Bytes(test.exponent))
+		if overflow := checkExpOverflow(base, exponent); overflow != test.overflow {
+			t.Errorf("test %d: %s ** %s: expected overflow %v, got %v", i, test.base, test.exponent, test.overflow, overflow)
+		}
+	}
+}
+
+func opBenchmark(bench *testing.B, op executionFunc, args ...string) {
 	var (
 		env   = NewEVM(Context{}, nil, params.TestChainConfig, Config{})
 		stack = newstack()
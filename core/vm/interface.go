@@ -45,6 +45,11 @@ type StateDB interface {
 	GetState(common.Address, common.Hash) common.Hash
 	SetState(common.Address, common.Hash, common.Hash)
 
+	// GetStateTaint and SetStateTaint access the shadow taint of a storage
+	// slot, which must be reverted together with the storage itself.
+	GetStateTaint(common.Address, common.Hash) int
+	SetStateTaint(common.Address, common.Hash, int)
+
 	Suicide(common.Address) bool
 	HasSuicided(common.Address) bool
 
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"sync/atomic"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/params"
 )
@@ -39,6 +40,20 @@ type Config struct {
 	// may be left uninitialised and will be set to the default
 	// table.
 	JumpTable [256]operation
+	// TaintAnalysis runs the taint analysis and fills the taint report. It
+	// adds the taint rules of taint_table.go to the instruction set. Without
+	// it the interpreter keeps no taint, e.g. when importing blocks.
+	TaintAnalysis bool
+	// TaintArgs describes the parameters of the called method, they are
+	// used to report which argument a finding is derived from. If nil, the
+	// parameters are inferred from the calldata.
+	TaintArgs []TaintArg
+	// TaintImplicitFlow also propagates taint from tainted branch
+	// conditions to the values computed under them.
+	TaintImplicitFlow bool
+	// Detectors are notified of every operation to record findings in
+	// the taint report. If nil, the DefaultDetectors are used.
+	Detectors []Detector
 }
 
 // Interpreter is used to run Ethereum based contracts and will utilise the
@@ -46,15 +61,17 @@ type Config struct {
 // The Interpreter will run the byte code VM based on the passed
 // configuration.
 type Interpreter struct {
-	evm          *EVM
-	cfg          Config
-	gasTable     params.GasTable
-	intPool      *intPool
-	taintIntPool *TaintIntPool
+	evm      *EVM
+	cfg      Config
+	gasTable params.GasTable
+	intPool  *intPool
 
 	readOnly   bool   // Whether to throw on stateful modifications
 	returnData []byte // Last CALL's return data for subsequent reuse
 	returnFlag []int  // Last CALL's return flag for subsequent reuse
+	callTaint  []int  // taint of the input of the call being made, see taint_table.go
+
+	controlFlows map[common.Hash]*controlFlow // control flow analysis by code hash
 }
 
 // NewInterpreter returns a new instance of the Interpreter.
@@ -75,16 +92,20 @@ func NewInterpreter(evm *EVM, cfg Config) *Interpreter {
 		}
 	}
 
-	// added for full instruction supporting
-	// set default: IsConstantinople
-	cfg.JumpTable = constantinopleInstructionSet
+	if cfg.TaintAnalysis {
+		cfg.JumpTable = newTaintInstructionSet(cfg.JumpTable)
+	}
+
+	if cfg.Detectors == nil {
+		cfg.Detectors = DefaultDetectors()
+	}
 
 	return &Interpreter{
 		evm:          evm,
 		cfg:          cfg,
 		gasTable:     evm.ChainConfig().GasTable(evm.BlockNumber),
 		intPool:      newIntPool(),
-		taintIntPool: newTaintIntPool(),
+		controlFlows: make(map[common.Hash]*controlFlow),
 	}
 }
 
@@ -107,13 +128,25 @@ func (in *Interpreter) enforceRestrictions(op OpCode, operation operation, stack
 // Run loops and evaluates the contract's code with the given input data and returns
 // the return byte-slice and an error if one occurred.
 //
+// The inputTaint holds the taint of every byte of the input. A nil inputTaint
+// means the input comes from outside the EVM and is entirely untrusted.
+//
 // It's important to note that any errors returned by the interpreter should be
 // considered a revert-and-consume-all-gas operation except for
 // errExecutionReverted which means revert-and-keep-gas-left.
-func (in *Interpreter) Run(contract *Contract, input []byte) (ret []byte, taintFlag []int, err error) {
+func (in *Interpreter) Run(contract *Contract, input []byte, inputTaint []int) (ret []byte, taintFlag []int, err error) {
 	// Increment the call depth which is restricted to 1024
 	in.evm.depth++
 	defer func() { in.evm.depth-- }()
+	if in.cfg.TaintAnalysis {
+		defer func() {
+			t_ret := SAFE_FLAG
+			for _, t := range taintFlag {
+				t_ret |= t
+			}
+			in.evm.taintReport.leave(in.evm.depth, err != nil, t_ret)
+		}()
+	}
 
 	// Reset the previous call's return data. It's unimportant to preserve the old buffer
 	// as every returning call will return new data anyway.
@@ -129,9 +162,6 @@ func (in *Interpreter) Run(contract *Contract, input []byte) (ret []byte, taintF
 		op    OpCode        // current opcode
 		mem   = NewMemory() // bound memory
 		stack = newstack()  // local stack
-
-		taint_mem   = NewTaintMemory()
-		taint_stack = newtaintstack()
 		// For optimisation reason we're using uint64 as the program counter.
 		// It's theoretically possible to go above 2^64. The YP defines the PC
 		// to be uint256. Practically much less so feasible.
@@ -144,6 +174,37 @@ func (in *Interpreter) Run(contract *Contract, input []byte) (ret []byte, taintF
 	)
 	contract.Input = input
 
+	// the taint state of the frame, only kept for the taint analysis
+	var (
+		taint_mem   *TaintMemory
+		taint_stack *TaintStack
+		shadow      *taintShadow
+		implicit    *implicitFlow
+		step        *DetectorStep
+		results     []DetectorResult
+	)
+	if in.cfg.TaintAnalysis {
+		if inputTaint == nil {
+			inputTaint = newCalldataTaint(input)
+		}
+		contract.InputTaint = inputTaint
+
+		taint_mem = NewTaintMemory()
+		taint_stack = newtaintstack()
+		shadow = newTaintShadow(in, contract, taint_stack, taint_mem)
+		if in.cfg.TaintImplicitFlow {
+			implicit = newImplicitFlow(in.controlFlow(contract))
+		}
+		step = &DetectorStep{
+			EVM:         in.evm,
+			Contract:    contract,
+			Stack:       stack,
+			Memory:      mem,
+			TaintStack:  taint_stack,
+			TaintMemory: taint_mem,
+		}
+	}
+
 	if in.cfg.Debug {
 		defer func() {
 			if err != nil {
@@ -202,7 +263,9 @@ func (in *Interpreter) Run(contract *Contract, input []byte) (ret []byte, taintF
 		}
 		if memorySize > 0 {
 			mem.Resize(memorySize)
-			taint_mem.Resize(memorySize)
+			if shadow != nil {
+				taint_mem.Resize(memorySize)
+			}
 		}
 
 		if in.cfg.Debug {
@@ -210,24 +273,54 @@ func (in *Interpreter) Run(contract *Contract, input []byte) (ret []byte, taintF
 			logged = true
 
 			// trace taint_stack and taint_mem
-			taint_stack.JPrint()
-			taint_mem.JPrint()
-			fmt.Println()
+			if shadow != nil {
+				taint_stack.JPrint()
+				taint_mem.JPrint()
+				fmt.Println()
+			}
 		}
 
-		// execute the operation
-		res, taintFlag, err := operation.execute(&pc, in.evm, contract, mem, stack, taint_mem, taint_stack)
-		// verifyPool is a build flag. Pool verification makes sure the integrity
-		// of the integer pool by comparing values to a default value.
-		if verifyPool {
-			verifyIntegerPool(in.intPool)
+		if shadow != nil {
+			if implicit != nil {
+				implicit.reach(pc)
+				if op == JUMPI {
+					implicit.branch(pc, taint_stack.Back(1))
+				}
+			}
+			step.Pc, step.Op = pc, op
+			results = captureOp(in.cfg.Detectors, step, results)
+			shadow.capture(operation.taint, stack)
 		}
+
+		// execute the operation
+		res, taintFlag, err := operation.execute(&pc, in.evm, contract, mem, stack)
 		// if the operation clears the return data (e.g. it has returning data)
 		// set the last return to the result of the operation.
 		if operation.returns {
 			in.returnData = res
 			in.returnFlag = taintFlag
 		}
+		if err == nil && shadow != nil {
+			if t_ret := shadow.apply(); t_ret != nil {
+				taintFlag = t_ret
+			}
+			for _, result := range results {
+				result(step)
+			}
+			if implicit != nil {
+				implicit.apply(op, taint_stack)
+			}
+		}
+		// verifyPool is a build flag. Pool verification makes sure the integrity
+		// of the integer pool by comparing values to a default value.
+		if verifyPool {
+			verifyIntegerPool(in.intPool)
+		}
+		// verifyTaint is a build flag. Taint verification makes sure the
+		// taint stack and memory keep shadowing the stack and memory.
+		if verifyTaint && err == nil && shadow != nil {
+			verifyTaintShadow(op, step.Pc, stack, mem, taint_stack, taint_mem)
+		}
 
 		switch {
 		case err != nil:
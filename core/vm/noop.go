@@ -59,6 +59,8 @@ func (NoopStateDB) AddRefund(uint64)
 func (NoopStateDB) GetRefund() uint64                                                  { return 0 }
 func (NoopStateDB) GetState(common.Address, common.Hash) common.Hash                   { return common.Hash{} }
 func (NoopStateDB) SetState(common.Address, common.Hash, common.Hash)                  {}
+func (NoopStateDB) GetStateTaint(common.Address, common.Hash) int                      { return SAFE_FLAG }
+func (NoopStateDB) SetStateTaint(common.Address, common.Hash, int)                     {}
 func (NoopStateDB) Suicide(common.Address) bool                                        { return false }
 func (NoopStateDB) HasSuicided(common.Address) bool                                    { return false }
 func (NoopStateDB) Exist(common.Address) bool                                          { return false }
@@ -44,21 +44,32 @@ type Config struct {
 	Debug       bool
 	EVMConfig   vm.Config
 
+	// NoTaintAnalysis runs the EVM without the taint analysis, the
+	// returned taint reports stay empty.
+	NoTaintAnalysis bool
+
 	State     *state.StateDB
 	GetHashFn func(n uint64) common.Hash
 }
 
 // sets defaults on the config
 func setDefaults(cfg *Config) {
+	// the runtime runs the taint analysis unless it is turned off
+	if !cfg.NoTaintAnalysis {
+		cfg.EVMConfig.TaintAnalysis = true
+	}
+
 	if cfg.ChainConfig == nil {
 		cfg.ChainConfig = &params.ChainConfig{
-			ChainID:        big.NewInt(1),
-			HomesteadBlock: new(big.Int),
-			DAOForkBlock:   new(big.Int),
-			DAOForkSupport: false,
-			EIP150Block:    new(big.Int),
-			EIP155Block:    new(big.Int),
-			EIP158Block:    new(big.Int),
+			ChainID:             big.NewInt(1),
+			HomesteadBlock:      new(big.Int),
+			DAOForkBlock:        new(big.Int),
+			DAOForkSupport:      false,
+			EIP150Block:         new(big.Int),
+			EIP155Block:         new(big.Int),
+			EIP158Block:         new(big.Int),
+			ByzantiumBlock:      new(big.Int),
+			ConstantinopleBlock: new(big.Int),
 		}
 	}
 
@@ -88,11 +99,12 @@ func setDefaults(cfg *Config) {
 }
 
 // Execute executes the code using the input as call data during the execution.
-// It returns the EVM's return value, the new state and an error if it failed.
+// It returns the EVM's return value, the new state, the taint analysis report
+// and an error if it failed.
 //
 // Executes sets up a in memory, temporarily, environment for the execution of
 // the given code. It makes sure that it's restored to it's original state afterwards.
-func Execute(code, input []byte, cfg *Config) ([]byte, *state.StateDB, error) {
+func Execute(code, input []byte, cfg *Config) ([]byte, *state.StateDB, *vm.TaintReport, error) {
 	if cfg == nil {
 		cfg = new(Config)
 	}
@@ -114,15 +126,16 @@ func Execute(code, input []byte, cfg *Config) ([]byte, *state.StateDB, error) {
 		sender,
 		common.BytesToAddress([]byte("contract")),
 		input,
+		nil,
 		cfg.GasLimit,
 		cfg.Value,
 	)
 
-	return ret, cfg.State, err
+	return ret, cfg.State, vmenv.TaintReport(), err
 }
 
 // Create executes the code using the EVM create method
-func Create(input []byte, cfg *Config) ([]byte, common.Address, uint64, error) {
+func Create(input []byte, cfg *Config) ([]byte, common.Address, uint64, *vm.TaintReport, error) {
 	if cfg == nil {
 		cfg = new(Config)
 	}
@@ -143,15 +156,15 @@ func Create(input []byte, cfg *Config) ([]byte, common.Address, uint64, error) {
 		cfg.GasLimit,
 		cfg.Value,
 	)
-	return code, address, leftOverGas, err
+	return code, address, leftOverGas, vmenv.TaintReport(), err
 }
 
 // Call executes the code given by the contract's address. It will return the
-// EVM's return value or an error if it failed.
+// EVM's return value and taint analysis report or an error if it failed.
 //
 // Call, unlike Execute, requires a config and also requires the State field to
 // be set.
-func Call(address common.Address, input []byte, cfg *Config) ([]byte, uint64, error) {
+func Call(address common.Address, input []byte, cfg *Config) ([]byte, uint64, *vm.TaintReport, error) {
 	setDefaults(cfg)
 
 	vmenv := NewEnv(cfg)
@@ -162,9 +175,10 @@ func Call(address common.Address, input []byte, cfg *Config) ([]byte, uint64, er
 		sender,
 		address,
 		input,
+		nil,
 		cfg.GasLimit,
 		cfg.Value,
 	)
 
-	return ret, leftOverGas, err
+	return ret, leftOverGas, vmenv.TaintReport(), err
 }
@@ -17,12 +17,19 @@
 package runtime
 
 import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
 	"math/big"
+	"path/filepath"
+	"reflect"
+	"regexp"
 	"strings"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/ethdb"
@@ -56,6 +63,70 @@ func TestDefaults(t *testing.T) {
 	}
 }
 
+func TestDefaultsTaintAnalysis(t *testing.T) {
+	cfg := new(Config)
+	setDefaults(cfg)
+	if !cfg.EVMConfig.TaintAnalysis {
+		t.Error("expected the taint analysis to be on")
+	}
+	if !cfg.ChainConfig.IsConstantinople(cfg.BlockNumber) {
+		t.Error("expected the constantinople rules")
+	}
+	cfg = &Config{NoTaintAnalysis: true}
+	setDefaults(cfg)
+	if cfg.EVMConfig.TaintAnalysis {
+		t.Error("expected the taint analysis to be off")
+	}
+}
+
+func TestTaintJumpTable(t *testing.T) {
+	// SHR is not part of the homestead instruction set
+	code := []byte{byte(vm.PUSH1), 1, byte(vm.PUSH1), 0, byte(vm.CALLDATALOAD), byte(vm.SHR), byte(vm.STOP)}
+	if _, _, _, err := Execute(code, nil, nil); err != nil {
+		t.Fatal("didn't expect error", err)
+	}
+	cfg := &Config{EVMConfig: vm.Config{JumpTable: vm.NewHomesteadInstructionSet()}}
+	if _, _, _, err := Execute(code, nil, cfg); err == nil {
+		t.Error("expected the homestead instruction set to reject SHR")
+	}
+}
+
+// TestPrestateOpcodes runs byzantium and constantinople opcodes with the
+// chain config of the prestate passed to the evm tool by taint_scripts.
+func TestPrestateOpcodes(t *testing.T) {
+	tests := []struct {
+		code []byte
+		ret  []byte
+	}{
+		// return 0x2a >> 1
+		{[]byte{
+			byte(vm.PUSH1), 0x2a, byte(vm.PUSH1), 1, byte(vm.SHR), byte(vm.PUSH1), 0, byte(vm.MSTORE8),
+			byte(vm.PUSH1), 1, byte(vm.PUSH1), 0, byte(vm.RETURN),
+		}, []byte{0x15}},
+		// revert with 0x2a
+		{[]byte{
+			byte(vm.PUSH1), 0x2a, byte(vm.PUSH1), 0, byte(vm.MSTORE8),
+			byte(vm.PUSH1), 1, byte(vm.PUSH1), 0, byte(vm.REVERT),
+		}, []byte{0x2a}},
+	}
+	for _, path := range []string{"../../../genesis-example.json", "../../../taint_scripts/genesis-example.json"} {
+		src, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		genesis := new(core.Genesis)
+		if err := json.Unmarshal(src, genesis); err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		for i, test := range tests {
+			cfg := &Config{ChainConfig: genesis.Config, BlockNumber: new(big.Int).SetUint64(genesis.Number)}
+			if ret, _, _, _ := Execute(test.code, nil, cfg); !bytes.Equal(ret, test.ret) {
+				t.Errorf("%s: test %d: got %x, want %x", path, i, ret, test.ret)
+			}
+		}
+	}
+}
+
 func TestEVM(t *testing.T) {
 	defer func() {
 		if r := recover(); r != nil {
@@ -75,7 +146,7 @@ func TestEVM(t *testing.T) {
 }
 
 func TestExecute(t *testing.T) {
-	ret, _, err := Execute([]byte{
+	ret, _, _, err := Execute([]byte{
 		byte(vm.PUSH1), 10,
 		byte(vm.PUSH1), 0,
 		byte(vm.MSTORE),
@@ -105,7 +176,7 @@ func TestCall(t *testing.T) {
 		byte(vm.RETURN),
 	})
 
-	ret, _, err := Call(address, nil, &Config{State: state})
+	ret, _, _, err := Call(address, nil, &Config{State: state})
 	if err != nil {
 		t.Fatal("didn't expect error", err)
 	}
@@ -116,6 +187,983 @@ func TestCall(t *testing.T) {
 	}
 }
 
+// taintAddCode adds the first calldata word to itself and returns the sum.
+var taintAddCode = []byte{
+	byte(vm.JUMPDEST),
+	byte(vm.PUSH1), 0,
+	byte(vm.CALLDATALOAD),
+	byte(vm.PUSH1), 0,
+	byte(vm.CALLDATALOAD),
+	byte(vm.ADD),
+	byte(vm.PUSH1), 0,
+	byte(vm.MSTORE),
+	byte(vm.PUSH1), 32,
+	byte(vm.PUSH1), 0,
+	byte(vm.RETURN),
+}
+
+func TestExecuteTaintReport(t *testing.T) {
+	_, _, report, err := Execute(taintAddCode, bytes.Repeat([]byte{0xff}, 32), nil)
+	if err != nil {
+		t.Fatal("didn't expect error", err)
+	}
+	if report.Result() != "overflow" {
+		t.Errorf("expected overflow, got %s", report.Result())
+	}
+	if len(report.Findings) != 1 {
+		t.Fatalf("expected 1 finding, got %d", len(report.Findings))
+	}
+	var (
+		f   = report.Findings[0]
+		max = new(big.Int).SetBytes(bytes.Repeat([]byte{0xff}, 32))
+	)
+	if f.Pc != 7 || f.Op != vm.ADD || f.Depth != 1 || f.Class() != "overflow" {
+		t.Errorf("unexpected finding: pc %d, op %v, depth %d, class %s", f.Pc, f.Op, f.Depth, f.Class())
+	}
+	if len(f.Operands) != 2 || f.Operands[0].Cmp(max) != 0 || f.Operands[1].Cmp(max) != 0 {
+		t.Errorf("unexpected operands: %v", f.Operands)
+	}
+	if f.Result.Cmp(new(big.Int).Sub(max, big.NewInt(1))) != 0 {
+		t.Errorf("unexpected result: %v", f.Result)
+	}
+	if f.Address != common.BytesToAddress([]byte("contract")) {
+		t.Errorf("unexpected address: %x", f.Address)
+	}
+}
+
+func TestTaintReportReset(t *testing.T) {
+	cfg := new(Config)
+	setDefaults(cfg)
+	cfg.State, _ = state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+
+	address := common.HexToAddress("0x0a")
+	cfg.State.SetCode(address, taintAddCode)
+
+	vmenv := NewEnv(cfg)
+	sender := vm.AccountRef(cfg.Origin)
+
+	// every top-level entry point starts a fresh report
+	tests := []struct {
+		name string
+		call func(input []byte)
+	}{
+		{"Call", func(input []byte) {
+			vmenv.Call(sender, address, input, nil, cfg.GasLimit, cfg.Value)
+		}},
+		{"CallCode", func(input []byte) {
+			vmenv.CallCode(sender, address, input, nil, cfg.GasLimit, cfg.Value)
+		}},
+		{"DelegateCall", func(input []byte) {
+			// a delegate call is made from within a contract
+			caller := vm.NewContract(sender, sender, new(big.Int), cfg.GasLimit)
+			vmenv.DelegateCall(caller, address, input, nil, cfg.GasLimit)
+		}},
+		{"StaticCall", func(input []byte) {
+			vmenv.StaticCall(sender, address, input, nil, cfg.GasLimit)
+		}},
+	}
+	for _, test := range tests {
+		test.call(bytes.Repeat([]byte{0xff}, 32))
+		first := vmenv.TaintReport()
+
+		test.call(common.LeftPadBytes([]byte{1}, 32))
+		second := vmenv.TaintReport()
+
+		if first.Result() != "overflow" {
+			t.Errorf("%s: first call: expected overflow, got %s", test.name, first.Result())
+		}
+		if second.Result() != "potential overflow" {
+			t.Errorf("%s: second call: expected potential overflow, got %s", test.name, second.Result())
+		}
+	}
+}
+
+func TestStorageTaint(t *testing.T) {
+	state, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	address := common.HexToAddress("0x0a")
+	// Store the first calldata word in slot 0 if there is calldata,
+	// otherwise add the value of slot 0 to itself.
+	state.SetCode(address, []byte{
+		byte(vm.CALLDATASIZE),
+		byte(vm.ISZERO),
+		byte(vm.PUSH1), 12,
+		byte(vm.JUMPI),
+		byte(vm.PUSH1), 0,
+		byte(vm.CALLDATALOAD),
+		byte(vm.PUSH1), 0,
+		byte(vm.SSTORE),
+		byte(vm.STOP),
+		byte(vm.JUMPDEST),
+		byte(vm.PUSH1), 0,
+		byte(vm.SLOAD),
+		byte(vm.DUP1),
+		byte(vm.ADD),
+		byte(vm.PUSH1), 0,
+		byte(vm.MSTORE),
+		byte(vm.PUSH1), 32,
+		byte(vm.PUSH1), 0,
+		byte(vm.RETURN),
+	})
+	if _, _, _, err := Call(address, bytes.Repeat([]byte{0xff}, 32), &Config{State: state}); err != nil {
+		t.Fatal("didn't expect error", err)
+	}
+	_, _, report, err := Call(address, nil, &Config{State: state})
+	if err != nil {
+		t.Fatal("didn't expect error", err)
+	}
+	if report.Result() != "overflow" {
+		t.Errorf("expected overflow, got %s", report.Result())
+	}
+	if labels := report.Findings[0].Labels(); !reflect.DeepEqual(labels, []string{"calldata", "storage"}) {
+		t.Errorf("expected calldata and storage labels, got %v", labels)
+	}
+}
+
+func TestStorageArgs(t *testing.T) {
+	state, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	address := common.HexToAddress("0x0a")
+	// With two arguments store arg1 in slot 0, otherwise add the value of
+	// slot 0 to itself.
+	state.SetCode(address, []byte{
+		byte(vm.PUSH1), 68, byte(vm.CALLDATASIZE), byte(vm.EQ), byte(vm.PUSH1), 20, byte(vm.JUMPI),
+		byte(vm.PUSH1), 0, byte(vm.SLOAD), byte(vm.DUP1), byte(vm.ADD),
+		byte(vm.PUSH1), 0, byte(vm.MSTORE), byte(vm.PUSH1), 32, byte(vm.PUSH1), 0, byte(vm.RETURN),
+		byte(vm.JUMPDEST), byte(vm.PUSH1), 36, byte(vm.CALLDATALOAD), byte(vm.PUSH1), 0, byte(vm.SSTORE), byte(vm.STOP),
+	})
+	input := func(words ...byte) []byte {
+		in := make([]byte, 4, 4+32*len(words))
+		for _, w := range words {
+			in = append(in, bytes.Repeat([]byte{w}, 32)...)
+		}
+		return in
+	}
+	if _, _, _, err := Call(address, input(0, 0xff), &Config{State: state}); err != nil {
+		t.Fatal("didn't expect error", err)
+	}
+	// the arg1 of the second call is not the one stored by the first
+	_, _, report, err := Call(address, input(0, 0xff, 0), &Config{State: state})
+	if err != nil {
+		t.Fatal("didn't expect error", err)
+	}
+	if report.Result() != "overflow" {
+		t.Fatalf("expected overflow, got %s", report.Result())
+	}
+	for i, refs := range report.Findings[0].Args {
+		if len(refs) != 0 {
+			t.Errorf("operand %d: expected no calldata words, got %v", i, refs)
+		}
+	}
+}
+
+func TestTaintSources(t *testing.T) {
+	tests := []struct {
+		source []byte
+		want   []string
+	}{
+		{[]byte{byte(vm.PUSH1), 0, byte(vm.CALLDATALOAD)}, []string{"calldata"}},
+		{[]byte{byte(vm.CALLVALUE)}, []string{"callvalue"}},
+		{[]byte{byte(vm.CALLER)}, []string{"caller"}},
+		{[]byte{byte(vm.ORIGIN)}, []string{"origin"}},
+		{[]byte{byte(vm.TIMESTAMP)}, []string{"timestamp"}},
+		{[]byte{byte(vm.NUMBER)}, []string{"number"}},
+		{[]byte{byte(vm.COINBASE)}, []string{"coinbase"}},
+		{[]byte{byte(vm.TIMESTAMP), byte(vm.CALLVALUE), byte(vm.ADD)}, []string{"callvalue", "timestamp"}},
+	}
+	for i, test := range tests {
+		code := append([]byte{byte(vm.JUMPDEST)}, test.source...)
+		code = append(code,
+			byte(vm.DUP1),
+			byte(vm.ADD),
+			byte(vm.PUSH1), 0,
+			byte(vm.MSTORE),
+			byte(vm.PUSH1), 32,
+			byte(vm.PUSH1), 0,
+			byte(vm.RETURN),
+		)
+		_, _, report, err := Execute(code, bytes.Repeat([]byte{0xff}, 32), nil)
+		if err != nil {
+			t.Fatalf("test %d: didn't expect error: %v", i, err)
+		}
+		if len(report.Findings) == 0 {
+			t.Fatalf("test %d: expected findings", i)
+		}
+		last := report.Findings[len(report.Findings)-1]
+		if labels := last.Labels(); !reflect.DeepEqual(labels, test.want) {
+			t.Errorf("test %d: expected labels %v, got %v", i, test.want, labels)
+		}
+	}
+}
+
+func TestOverflowGuard(t *testing.T) {
+	// overflowCode adds 1 to the first calldata word x, leaving x and the
+	// result r on the stack, followed by the given check.
+	overflowCode := func(check ...byte) []byte {
+		return append([]byte{
+			byte(vm.JUMPDEST),
+			byte(vm.PUSH1), 0,
+			byte(vm.CALLDATALOAD),
+			byte(vm.PUSH1), 1,
+			byte(vm.DUP2),
+			byte(vm.ADD),
+		}, check...)
+	}
+	tests := []struct {
+		code []byte
+		want string
+	}{
+		// require(r >= x)
+		{overflowCode(
+			byte(vm.DUP2), byte(vm.DUP2), byte(vm.LT), byte(vm.ISZERO), byte(vm.PUSH1), 19, byte(vm.JUMPI),
+			byte(vm.PUSH1), 0, byte(vm.DUP1), byte(vm.REVERT),
+			byte(vm.JUMPDEST), byte(vm.PUSH1), 0, byte(vm.SSTORE), byte(vm.STOP),
+		), "protected overflow"},
+		// if (r < x) return; the early return throws the result away
+		{overflowCode(
+			byte(vm.DUP2), byte(vm.DUP2), byte(vm.LT), byte(vm.PUSH1), 18, byte(vm.JUMPI),
+			byte(vm.PUSH1), 0, byte(vm.SSTORE), byte(vm.STOP),
+			byte(vm.JUMPDEST), byte(vm.STOP),
+		), "protected overflow"},
+		// if (r < 100) {}; return r;
+		{overflowCode(
+			byte(vm.DUP1), byte(vm.PUSH1), 100, byte(vm.GT), byte(vm.PUSH1), 15, byte(vm.JUMPI),
+			byte(vm.JUMPDEST), byte(vm.PUSH1), 0, byte(vm.MSTORE), byte(vm.PUSH1), 32, byte(vm.PUSH1), 0, byte(vm.RETURN),
+		), "overflow"},
+		// require(r <= x) does not catch the overflow
+		{overflowCode(
+			byte(vm.DUP2), byte(vm.DUP2), byte(vm.GT), byte(vm.ISZERO), byte(vm.PUSH1), 19, byte(vm.JUMPI),
+			byte(vm.PUSH1), 0, byte(vm.DUP1), byte(vm.REVERT),
+			byte(vm.JUMPDEST), byte(vm.PUSH1), 0, byte(vm.SSTORE), byte(vm.STOP),
+		), "overflow"},
+		// unchecked
+		{overflowCode(byte(vm.PUSH1), 0, byte(vm.SSTORE), byte(vm.STOP)), "overflow"},
+	}
+	for i, test := range tests {
+		_, _, report, _ := Execute(test.code, bytes.Repeat([]byte{0xff}, 32), nil)
+		if report.Result() != test.want {
+			t.Errorf("test %d: expected %s, got %s", i, test.want, report.Result())
+		}
+		// the verdict is the one of the finding
+		if f := report.Ranked()[0]; f.Class() != test.want {
+			t.Errorf("test %d: expected %s finding, got %s", i, test.want, f.Class())
+		}
+	}
+}
+
+func TestExpOverflow(t *testing.T) {
+	// expCode raises the first calldata argument to the given power
+	expCode := func(exponent byte) []byte {
+		return []byte{byte(vm.PUSH1), exponent, byte(vm.PUSH1), 4, byte(vm.CALLDATALOAD), byte(vm.EXP), byte(vm.STOP)}
+	}
+	tests := []struct {
+		code []byte
+		base byte
+		want string
+	}{
+		{expCode(1), 0, "potential overflow"},
+		{expCode(0xff), 1, "potential overflow"},
+		{expCode(3), 2, "potential overflow"},
+		{expCode(0xff), 2, "potential overflow"},
+		{expCode(0xff), 3, "overflow"},
+	}
+	for i, test := range tests {
+		input := append([]byte{1, 2, 3, 4}, common.LeftPadBytes([]byte{test.base}, 32)...)
+		_, _, report, err := Execute(test.code, input, nil)
+		if err != nil {
+			t.Fatalf("test %d: didn't expect error: %v", i, err)
+		}
+		if report.Result() != test.want {
+			t.Errorf("test %d: expected %s, got %s", i, test.want, report.Result())
+		}
+	}
+}
+
+func TestOverflowGuardLabels(t *testing.T) {
+	// overflowCode overflows x+1 five times, drops all but the last result
+	// and runs check on it.
+	overflowCode := func(check ...byte) []byte {
+		var code []byte
+		for i := 0; i < 5; i++ {
+			code = append(code, byte(vm.PUSH1), 0, byte(vm.CALLDATALOAD), byte(vm.PUSH1), 1, byte(vm.ADD))
+			if i < 4 {
+				code = append(code, byte(vm.POP))
+			}
+		}
+		return append(code, check...)
+	}
+	tests := []struct {
+		code []byte
+		want string // class of the fifth finding
+		sink string
+	}{
+		// the last result is stored
+		{overflowCode(byte(vm.PUSH1), 0, byte(vm.SSTORE), byte(vm.STOP)), "overflow", "sstore"},
+		// require(r != 0)
+		{overflowCode(
+			byte(vm.PUSH1), 39, byte(vm.JUMPI),
+			byte(vm.PUSH1), 0, byte(vm.DUP1), byte(vm.REVERT),
+			byte(vm.JUMPDEST), byte(vm.STOP),
+		), "protected overflow", "dropped"},
+	}
+	for i, test := range tests {
+		_, _, report, _ := Execute(test.code, bytes.Repeat([]byte{0xff}, 32), nil)
+		if len(report.Findings) != 5 {
+			t.Fatalf("test %d: expected 5 findings, got %d", i, len(report.Findings))
+		}
+		// the fifth takes over the label of the first, the first keeps its
+		// verdict and sink
+		for n, f := range report.Findings[:4] {
+			if f.Class() != "overflow" || f.Sink() != "dropped" {
+				t.Errorf("test %d: finding %d: expected dropped overflow, got %s %s", i, n, f.Sink(), f.Class())
+			}
+		}
+		fifth := report.Findings[4]
+		if fifth.Class() != test.want || fifth.Sink() != test.sink {
+			t.Errorf("test %d: fifth finding: expected %s %s, got %s %s", i, test.sink, test.want, fifth.Sink(), fifth.Class())
+		}
+		if test.sink == "sstore" && (fifth.Severity() != "critical" || report.Ranked()[0] != fifth) {
+			t.Errorf("test %d: expected the fifth finding to rank first as critical, got %s", i, fifth.Severity())
+		}
+	}
+}
+
+func TestOverflowGuardAcrossTransactions(t *testing.T) {
+	state, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	address := common.HexToAddress("0x0a")
+	// With one calldata word store x+1 in slot 0. Otherwise drop x+1 and
+	// revert on a JUMPI deciding on slot 0.
+	state.SetCode(address, []byte{
+		byte(vm.PUSH1), 32, byte(vm.CALLDATASIZE), byte(vm.EQ), byte(vm.PUSH1), 29, byte(vm.JUMPI),
+		byte(vm.PUSH1), 0, byte(vm.CALLDATALOAD), byte(vm.PUSH1), 1, byte(vm.ADD), byte(vm.POP),
+		byte(vm.PUSH1), 0, byte(vm.SLOAD), byte(vm.PUSH1), 24, byte(vm.JUMPI),
+		byte(vm.PUSH1), 0, byte(vm.DUP1), byte(vm.REVERT),
+		byte(vm.JUMPDEST), byte(vm.PUSH1), 0, byte(vm.DUP1), byte(vm.REVERT),
+		byte(vm.JUMPDEST), byte(vm.PUSH1), 0, byte(vm.CALLDATALOAD), byte(vm.PUSH1), 1, byte(vm.ADD),
+		byte(vm.PUSH1), 0, byte(vm.SSTORE), byte(vm.STOP),
+	})
+	_, _, report, err := Call(address, bytes.Repeat([]byte{0xff}, 32), &Config{State: state})
+	if err != nil {
+		t.Fatal("didn't expect error", err)
+	}
+	if report.Result() != "overflow" {
+		t.Errorf("first call: expected overflow, got %s", report.Result())
+	}
+	// the guard label of the stored overflow is not the one of the dropped
+	_, _, report, _ = Call(address, bytes.Repeat([]byte{0xff}, 64), &Config{State: state})
+	if report.Result() != "overflow" {
+		t.Errorf("second call: expected overflow, got %s", report.Result())
+	}
+}
+
+func TestUnderflow(t *testing.T) {
+	// 1 - x
+	code := []byte{
+		byte(vm.PUSH1), 0, byte(vm.CALLDATALOAD), byte(vm.PUSH1), 1, byte(vm.SUB),
+		byte(vm.PUSH1), 0, byte(vm.SSTORE), byte(vm.STOP),
+	}
+	tests := []struct {
+		input []byte
+		want  string
+	}{
+		{common.LeftPadBytes([]byte{1}, 32), "potential underflow"},
+		{common.LeftPadBytes([]byte{2}, 32), "underflow"},
+	}
+	for i, test := range tests {
+		_, _, report, _ := Execute(code, test.input, nil)
+		if report.Result() != test.want {
+			t.Errorf("test %d: expected %s, got %s", i, test.want, report.Result())
+		}
+	}
+}
+
+func TestSignedOverflow(t *testing.T) {
+	var (
+		intMax = append([]byte{0x7f}, bytes.Repeat([]byte{0xff}, 31)...)
+		intMin = append([]byte{0x80}, make([]byte, 31)...)
+	)
+	tests := []struct {
+		code  []byte
+		input []byte
+		want  string
+	}{
+		// x + 1 with x never used as signed
+		{[]byte{
+			byte(vm.PUSH1), 0, byte(vm.CALLDATALOAD), byte(vm.PUSH1), 1, byte(vm.ADD),
+			byte(vm.PUSH1), 0, byte(vm.SSTORE), byte(vm.STOP),
+		}, intMax, "potential overflow"},
+		// x < 0 makes x signed before x + 1
+		{[]byte{
+			byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.CALLDATALOAD), byte(vm.SLT), byte(vm.POP),
+			byte(vm.PUSH1), 0, byte(vm.CALLDATALOAD), byte(vm.PUSH1), 1, byte(vm.ADD),
+			byte(vm.PUSH1), 0, byte(vm.SSTORE), byte(vm.STOP),
+		}, intMax, "signed overflow"},
+		// require(x + 1 > 0)
+		{[]byte{
+			byte(vm.PUSH1), 0, byte(vm.CALLDATALOAD), byte(vm.PUSH1), 1, byte(vm.ADD),
+			byte(vm.DUP1), byte(vm.PUSH1), 0, byte(vm.SLT), byte(vm.PUSH1), 17, byte(vm.JUMPI),
+			byte(vm.PUSH1), 0, byte(vm.DUP1), byte(vm.REVERT),
+			byte(vm.JUMPDEST), byte(vm.PUSH1), 0, byte(vm.SSTORE), byte(vm.STOP),
+		}, intMax, "protected signed overflow"},
+		// x / -1
+		{[]byte{
+			byte(vm.PUSH1), 0, byte(vm.NOT), byte(vm.PUSH1), 0, byte(vm.CALLDATALOAD), byte(vm.SDIV),
+			byte(vm.PUSH1), 0, byte(vm.SSTORE), byte(vm.STOP),
+		}, intMin, "signed overflow"},
+		// int8(x + 1)
+		{[]byte{
+			byte(vm.PUSH1), 0, byte(vm.CALLDATALOAD), byte(vm.PUSH1), 1, byte(vm.ADD),
+			byte(vm.PUSH1), 0, byte(vm.SIGNEXTEND),
+			byte(vm.PUSH1), 0, byte(vm.SSTORE), byte(vm.STOP),
+		}, common.LeftPadBytes([]byte{0xff}, 32), "signed overflow"},
+		// int8(x) converts an input, it does not overflow
+		{[]byte{
+			byte(vm.PUSH1), 0, byte(vm.CALLDATALOAD), byte(vm.PUSH1), 0, byte(vm.SIGNEXTEND),
+			byte(vm.PUSH1), 0, byte(vm.SSTORE), byte(vm.STOP),
+		}, common.LeftPadBytes([]byte{1, 0}, 32), "safe"},
+		{[]byte{
+			byte(vm.PUSH1), 0, byte(vm.CALLDATALOAD), byte(vm.PUSH1), 0, byte(vm.SIGNEXTEND),
+			byte(vm.PUSH1), 0, byte(vm.SSTORE), byte(vm.STOP),
+		}, common.LeftPadBytes([]byte{5}, 32), "safe"},
+	}
+	for i, test := range tests {
+		_, _, report, _ := Execute(test.code, test.input, nil)
+		if report.Result() != test.want {
+			t.Errorf("test %d: expected %s, got %s", i, test.want, report.Result())
+		}
+	}
+}
+
+func TestNarrowOverflow(t *testing.T) {
+	var (
+		// uint8(x + 1)
+		uint8Add = []byte{
+			byte(vm.PUSH1), 0, byte(vm.CALLDATALOAD), byte(vm.PUSH1), 1, byte(vm.ADD), byte(vm.PUSH1), 0xff, byte(vm.AND),
+			byte(vm.PUSH1), 0, byte(vm.SSTORE), byte(vm.STOP),
+		}
+		// uint8(x)
+		uint8Cast = []byte{
+			byte(vm.PUSH1), 0, byte(vm.CALLDATALOAD), byte(vm.PUSH1), 0xff, byte(vm.AND),
+			byte(vm.PUSH1), 0, byte(vm.SSTORE), byte(vm.STOP),
+		}
+		// int8(x + 1)
+		int8Add = []byte{
+			byte(vm.PUSH1), 0, byte(vm.CALLDATALOAD), byte(vm.PUSH1), 1, byte(vm.ADD), byte(vm.PUSH1), 0, byte(vm.SIGNEXTEND),
+			byte(vm.PUSH1), 0, byte(vm.SSTORE), byte(vm.STOP),
+		}
+	)
+	tests := []struct {
+		code  []byte
+		input byte
+		want  string
+	}{
+		{uint8Add, 0xfe, "potential overflow"},
+		{uint8Add, 0xff, "overflow"},
+		{uint8Cast, 0xff, "safe"},
+		{int8Add, 0x01, "potential overflow"},
+		{int8Add, 0x7f, "signed overflow"},
+	}
+	for i, test := range tests {
+		_, _, report, _ := Execute(test.code, common.LeftPadBytes([]byte{test.input}, 32), nil)
+		if report.Result() != test.want {
+			t.Errorf("test %d: expected %s, got %s", i, test.want, report.Result())
+		}
+	}
+}
+
+func TestShlOverflow(t *testing.T) {
+	var (
+		// x << 8
+		unchecked = []byte{
+			byte(vm.PUSH1), 0, byte(vm.CALLDATALOAD), byte(vm.PUSH1), 8, byte(vm.SHL),
+			byte(vm.PUSH1), 0, byte(vm.SSTORE), byte(vm.STOP),
+		}
+		// require((x << 8) >> 8 == x)
+		checked = []byte{
+			byte(vm.PUSH1), 0, byte(vm.CALLDATALOAD), byte(vm.DUP1), byte(vm.PUSH1), 8, byte(vm.SHL),
+			byte(vm.PUSH1), 8, byte(vm.SHR), byte(vm.EQ), byte(vm.PUSH1), 18, byte(vm.JUMPI),
+			byte(vm.PUSH1), 0, byte(vm.DUP1), byte(vm.REVERT),
+			byte(vm.JUMPDEST), byte(vm.PUSH1), 1, byte(vm.PUSH1), 0, byte(vm.SSTORE), byte(vm.STOP),
+		}
+	)
+	tests := []struct {
+		code  []byte
+		input []byte
+		want  string
+	}{
+		{unchecked, common.LeftPadBytes([]byte{1}, 32), "potential overflow"},
+		{unchecked, bytes.Repeat([]byte{0xff}, 32), "overflow"},
+		{checked, bytes.Repeat([]byte{0xff}, 32), "protected overflow"},
+	}
+	for i, test := range tests {
+		_, _, report, _ := Execute(test.code, test.input, nil)
+		if report.Result() != test.want {
+			t.Errorf("test %d: expected %s, got %s", i, test.want, report.Result())
+		}
+	}
+}
+
+func TestOverflowSink(t *testing.T) {
+	// sinkCode computes x + 1 on the first calldata word, followed by the
+	// given use of the result.
+	sinkCode := func(use ...byte) []byte {
+		return append([]byte{byte(vm.PUSH1), 0, byte(vm.CALLDATALOAD), byte(vm.PUSH1), 1, byte(vm.ADD)}, use...)
+	}
+	tests := []struct {
+		code []byte
+		want string
+	}{
+		{sinkCode(byte(vm.POP), byte(vm.STOP)), "dropped"},
+		{sinkCode(byte(vm.PUSH1), 0, byte(vm.MSTORE), byte(vm.PUSH1), 32, byte(vm.PUSH1), 0, byte(vm.RETURN)), "return"},
+		{sinkCode(byte(vm.PUSH1), 0, byte(vm.MSTORE), byte(vm.PUSH1), 32, byte(vm.PUSH1), 0, byte(vm.LOG0)), "log"},
+		{sinkCode(byte(vm.PUSH1), 0, byte(vm.SSTORE)), "sstore"},
+		// the most severe of several sinks is kept
+		{sinkCode(byte(vm.DUP1), byte(vm.PUSH1), 0, byte(vm.MSTORE), byte(vm.PUSH1), 0, byte(vm.SSTORE)), "sstore"},
+	}
+	for i, test := range tests {
+		_, _, report, _ := Execute(test.code, bytes.Repeat([]byte{0xff}, 32), nil)
+		if len(report.Findings) != 1 {
+			t.Fatalf("test %d: expected 1 finding, got %d", i, len(report.Findings))
+		}
+		if sink := report.Findings[0].Sink(); sink != test.want {
+			t.Errorf("test %d: expected sink %s, got %s", i, test.want, sink)
+		}
+	}
+}
+
+func TestImplicitFlow(t *testing.T) {
+	// amount := 0; if (x > 10) amount = 1000; amount + 1
+	code := []byte{
+		byte(vm.PUSH1), 0,
+		byte(vm.PUSH1), 10, byte(vm.PUSH1), 0, byte(vm.CALLDATALOAD), byte(vm.GT), byte(vm.ISZERO),
+		byte(vm.PUSH1), 16, byte(vm.JUMPI),
+		byte(vm.POP), byte(vm.PUSH2), 0x03, 0xe8,
+		byte(vm.JUMPDEST), byte(vm.PUSH1), 1, byte(vm.ADD),
+		byte(vm.PUSH1), 0, byte(vm.SSTORE), byte(vm.STOP),
+	}
+	input := common.LeftPadBytes([]byte{11}, 32)
+
+	_, _, report, err := Execute(code, input, nil)
+	if err != nil {
+		t.Fatal("didn't expect error", err)
+	}
+	if report.Result() != "safe" {
+		t.Errorf("expected safe without implicit flow, got %s", report.Result())
+	}
+	_, _, report, err = Execute(code, input, &Config{EVMConfig: vm.Config{TaintImplicitFlow: true}})
+	if err != nil {
+		t.Fatal("didn't expect error", err)
+	}
+	if report.Result() != "potential overflow" {
+		t.Errorf("expected potential overflow with implicit flow, got %s", report.Result())
+	}
+}
+
+type detectorFunc func(step *vm.DetectorStep) vm.DetectorResult
+
+func (f detectorFunc) CaptureOp(step *vm.DetectorStep) vm.DetectorResult { return f(step) }
+
+func TestDetectors(t *testing.T) {
+	code := []byte{
+		byte(vm.PUSH1), 0, byte(vm.CALLDATALOAD), byte(vm.PUSH1), 1, byte(vm.ADD),
+		byte(vm.PUSH1), 0, byte(vm.SSTORE), byte(vm.STOP),
+	}
+	// report every store of a tainted value, after the value is stored
+	stores := detectorFunc(func(step *vm.DetectorStep) vm.DetectorResult {
+		if step.Op != vm.SSTORE || step.TaintStack.Back(1)&vm.SOURCE_FLAGS == 0 {
+			return nil
+		}
+		taint, value := step.TaintStack.Back(1), step.Operand(1)
+		return func(step *vm.DetectorStep) {
+			step.Record(vm.POTENTIAL_OVERFLOW_FLAG, []int{taint}, value, value)
+		}
+	})
+	tests := []struct {
+		detectors []vm.Detector
+		want      string
+		findings  []vm.OpCode
+	}{
+		{nil, "overflow", []vm.OpCode{vm.ADD}},
+		{[]vm.Detector{}, "safe", nil},
+		{[]vm.Detector{stores}, "potential overflow", []vm.OpCode{vm.SSTORE}},
+		{append(vm.DefaultDetectors(), stores), "overflow", []vm.OpCode{vm.ADD, vm.SSTORE}},
+	}
+	for i, test := range tests {
+		_, _, report, err := Execute(code, bytes.Repeat([]byte{0xff}, 32), &Config{EVMConfig: vm.Config{Detectors: test.detectors}})
+		if err != nil {
+			t.Fatalf("test %d: didn't expect error: %v", i, err)
+		}
+		if report.Result() != test.want {
+			t.Errorf("test %d: expected %s, got %s", i, test.want, report.Result())
+		}
+		var ops []vm.OpCode
+		for _, f := range report.Findings {
+			ops = append(ops, f.Op)
+		}
+		if !reflect.DeepEqual(ops, test.findings) {
+			t.Errorf("test %d: expected findings at %v, got %v", i, test.findings, ops)
+		}
+	}
+}
+
+func TestReentrancy(t *testing.T) {
+	// callCaller reads slot 0, calls the caller with the given value and gas,
+	// then writes the given slot.
+	callCaller := func(value byte, gas []byte, slot byte) []byte {
+		code := []byte{
+			byte(vm.PUSH1), 0, byte(vm.SLOAD), byte(vm.POP),
+			byte(vm.PUSH1), 0,
+			byte(vm.PUSH1), 0,
+			byte(vm.PUSH1), 0,
+			byte(vm.PUSH1), 0,
+			byte(vm.PUSH1), value,
+			byte(vm.CALLER),
+		}
+		code = append(code, gas...)
+		return append(code,
+			byte(vm.CALL), byte(vm.POP),
+			byte(vm.PUSH1), 1, byte(vm.PUSH1), slot, byte(vm.SSTORE),
+			byte(vm.STOP),
+		)
+	}
+	tests := []struct {
+		code  []byte
+		want  string
+		slots int
+	}{
+		// forwarding all gas to the caller before updating the slot
+		{callCaller(0, []byte{byte(vm.GAS)}, 0), "reentrancy", 1},
+		// sending value with more gas than the stipend
+		{callCaller(1, []byte{byte(vm.PUSH2), 0x08, 0xfd}, 0), "reentrancy", 1},
+		// the stipend alone cannot write storage
+		{callCaller(0, []byte{byte(vm.PUSH2), 0x08, 0xfc}, 0), "safe", 0},
+		// msg.sender.transfer(1) before the slot is written
+		{callCaller(1, []byte{byte(vm.PUSH1), 0}, 0), "safe", 0},
+		// sending value with the stipend as gas
+		{callCaller(1, []byte{byte(vm.PUSH2), 0x08, 0xfc}, 0), "safe", 0},
+		// the written slot was not read before the call
+		{callCaller(0, []byte{byte(vm.GAS)}, 1), "safe", 0},
+		// calling a constant address is trusted
+		{[]byte{byte(vm.PUSH1), 0, byte(vm.SLOAD), byte(vm.POP),
+			byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0,
+			byte(vm.PUSH1), 0x0a, byte(vm.GAS), byte(vm.CALL), byte(vm.POP),
+			byte(vm.PUSH1), 1, byte(vm.PUSH1), 0, byte(vm.SSTORE), byte(vm.STOP)}, "safe", 0},
+	}
+	input := []byte{0xa9, 0x05, 0x9c, 0xbb}
+	cfg := &Config{EVMConfig: vm.Config{Detectors: []vm.Detector{vm.NewReentrancyDetector()}}}
+	for i, test := range tests {
+		_, _, report, err := Execute(test.code, input, cfg)
+		if err != nil {
+			t.Fatalf("test %d: didn't expect error: %v", i, err)
+		}
+		if report.Result() != test.want {
+			t.Errorf("test %d: expected %s, got %s", i, test.want, report.Result())
+		}
+		if test.slots == 0 {
+			continue
+		}
+		f := report.Findings[len(report.Findings)-1]
+		if f.Op != vm.CALL || len(f.Slots) != test.slots || !bytes.Equal(f.Selector, input) {
+			t.Errorf("test %d: unexpected finding %v at %v of %x", i, f.Slots, f.Op, f.Selector)
+		}
+	}
+}
+
+func TestUncheckedCall(t *testing.T) {
+	call := []byte{
+		byte(vm.PUSH1), 0,
+		byte(vm.PUSH1), 0,
+		byte(vm.PUSH1), 0,
+		byte(vm.PUSH1), 0,
+		byte(vm.PUSH1), 0,
+		byte(vm.PUSH1), 0x0a,
+		byte(vm.GAS),
+		byte(vm.CALL),
+	}
+	// callCode calls 0x0a and then runs check on the success flag.
+	callCode := func(check ...byte) []byte {
+		code := append([]byte{}, call...)
+		return append(code, check...)
+	}
+	tests := []struct {
+		code []byte
+		want string
+	}{
+		// the flag is dropped
+		{callCode(byte(vm.POP), byte(vm.STOP)), "unchecked call"},
+		// require(success)
+		{callCode(byte(vm.ISZERO), byte(vm.PUSH1), 19, byte(vm.JUMPI), byte(vm.STOP), byte(vm.JUMPDEST), byte(vm.STOP)), "safe"},
+		// the flag is returned to the caller
+		{callCode(byte(vm.PUSH1), 0, byte(vm.MSTORE), byte(vm.PUSH1), 32, byte(vm.PUSH1), 0, byte(vm.RETURN)), "safe"},
+		// a reverted frame undoes the call
+		{callCode(byte(vm.POP), byte(vm.PUSH1), 0, byte(vm.DUP1), byte(vm.REVERT)), "safe"},
+		// a.call(); require(b.call()) leaves the first call unchecked
+		{callCode(append(append([]byte{byte(vm.POP)}, call...), byte(vm.ISZERO), byte(vm.PUSH1), 34, byte(vm.JUMPI), byte(vm.STOP), byte(vm.JUMPDEST), byte(vm.STOP))...), "unchecked call"},
+	}
+	for i, test := range tests {
+		_, _, report, _ := Execute(test.code, nil, nil)
+		if report.Result() != test.want {
+			t.Errorf("test %d: expected %s, got %s", i, test.want, report.Result())
+		}
+		if test.want != "safe" && (len(report.Findings) != 1 || report.Findings[0].Op != vm.CALL || report.Findings[0].Pc != 13) {
+			t.Errorf("test %d: unexpected findings %v", i, report.Findings)
+		}
+	}
+}
+
+func TestUncheckedCallAcrossTransactions(t *testing.T) {
+	state, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	address := common.HexToAddress("0x0b")
+	call := []byte{
+		byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0,
+		byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0x0a,
+		byte(vm.GAS), byte(vm.CALL),
+	}
+	// With calldata store the success of a call in slot 0. Otherwise drop
+	// the success of a call and branch on slot 0.
+	code := []byte{byte(vm.CALLDATASIZE), byte(vm.PUSH1), 28, byte(vm.JUMPI)}
+	code = append(code, call...)
+	code = append(code,
+		byte(vm.POP), byte(vm.PUSH1), 0, byte(vm.SLOAD), byte(vm.PUSH1), 26, byte(vm.JUMPI),
+		byte(vm.STOP), byte(vm.JUMPDEST), byte(vm.STOP),
+		byte(vm.JUMPDEST),
+	)
+	code = append(code, call...)
+	code = append(code, byte(vm.PUSH1), 0, byte(vm.SSTORE), byte(vm.STOP))
+	state.SetCode(address, code)
+
+	if _, _, _, err := Call(address, []byte{1}, &Config{State: state}); err != nil {
+		t.Fatal("didn't expect error", err)
+	}
+	// the stored success flag does not check the call of the second
+	_, _, report, err := Call(address, nil, &Config{State: state})
+	if err != nil {
+		t.Fatal("didn't expect error", err)
+	}
+	if report.Result() != "unchecked call" {
+		t.Errorf("expected unchecked call, got %s", report.Result())
+	}
+}
+
+func TestOriginAuth(t *testing.T) {
+	// checkOrigin compares tx.origin with the value pushed by other and
+	// branches on the result.
+	checkOrigin := func(other ...byte) []byte {
+		code := append([]byte{byte(vm.ORIGIN)}, other...)
+		dest := byte(len(code) + 5)
+		return append(code,
+			byte(vm.EQ), byte(vm.PUSH1), dest, byte(vm.JUMPI),
+			byte(vm.STOP), byte(vm.JUMPDEST), byte(vm.STOP),
+		)
+	}
+	tests := []struct {
+		code []byte
+		want string
+	}{
+		// require(tx.origin == owner)
+		{checkOrigin(byte(vm.PUSH1), 0, byte(vm.SLOAD)), "tx.origin authorization"},
+		// require(tx.origin == msg.sender) keeps contracts out
+		{checkOrigin(byte(vm.CALLER)), "safe"},
+		// the comparison does not decide a branch
+		{[]byte{byte(vm.ORIGIN), byte(vm.PUSH1), 0, byte(vm.SLOAD), byte(vm.EQ), byte(vm.PUSH1), 0, byte(vm.SSTORE)}, "safe"},
+	}
+	for i, test := range tests {
+		_, _, report, err := Execute(test.code, nil, nil)
+		if err != nil {
+			t.Fatalf("test %d: didn't expect error: %v", i, err)
+		}
+		if report.Result() != test.want {
+			t.Errorf("test %d: expected %s, got %s", i, test.want, report.Result())
+		}
+	}
+}
+
+func TestTaintedTarget(t *testing.T) {
+	// callTarget calls or delegates to the address pushed by target.
+	callTarget := func(op vm.OpCode, target ...byte) []byte {
+		code := []byte{byte(vm.PUSH1), 0, byte(vm.DUP1), byte(vm.DUP1), byte(vm.DUP1)}
+		if op == vm.CALL {
+			code = append(code, byte(vm.DUP1))
+		}
+		code = append(code, target...)
+		return append(code, byte(vm.GAS), byte(op), byte(vm.ISZERO), byte(vm.PUSH1), 0, byte(vm.JUMPI))
+	}
+	calldata := []byte{byte(vm.PUSH1), 0, byte(vm.CALLDATALOAD)}
+	tests := []struct {
+		code []byte
+		want string
+	}{
+		{callTarget(vm.CALL, calldata...), "tainted target"},
+		{callTarget(vm.DELEGATECALL, calldata...), "tainted target"},
+		{callTarget(vm.CALL, byte(vm.PUSH1), 0x0a), "safe"},
+		{callTarget(vm.DELEGATECALL, byte(vm.CALLER)), "safe"},
+		{append(calldata, byte(vm.SELFDESTRUCT)), "tainted target"},
+		{[]byte{byte(vm.CALLER), byte(vm.SELFDESTRUCT)}, "safe"},
+	}
+	for i, test := range tests {
+		_, _, report, _ := Execute(test.code, common.LeftPadBytes([]byte{0x0a}, 32), nil)
+		if report.Result() != test.want {
+			t.Errorf("test %d: expected %s, got %s", i, test.want, report.Result())
+		}
+	}
+}
+
+func TestTaintedJump(t *testing.T) {
+	tests := []struct {
+		code     []byte
+		implicit bool
+		want     string
+	}{
+		// jump to the destination given in calldata
+		{[]byte{byte(vm.PUSH1), 0, byte(vm.CALLDATALOAD), byte(vm.JUMP), byte(vm.JUMPDEST), byte(vm.STOP)}, false, "tainted jump"},
+		// jump to a constant
+		{[]byte{byte(vm.PUSH1), 3, byte(vm.JUMP), byte(vm.JUMPDEST), byte(vm.STOP)}, false, "safe"},
+		// jump to a constant under a tainted branch
+		{[]byte{
+			byte(vm.PUSH1), 0, byte(vm.CALLDATALOAD), byte(vm.PUSH1), 7, byte(vm.JUMPI), byte(vm.STOP),
+			byte(vm.JUMPDEST), byte(vm.PUSH1), 12, byte(vm.JUMP), byte(vm.STOP),
+			byte(vm.JUMPDEST), byte(vm.STOP),
+		}, true, "safe"},
+	}
+	input := common.LeftPadBytes([]byte{4}, 32)
+	for i, test := range tests {
+		cfg := &Config{EVMConfig: vm.Config{TaintImplicitFlow: test.implicit}}
+		_, _, report, err := Execute(test.code, input, cfg)
+		if err != nil {
+			t.Fatalf("test %d: didn't expect error: %v", i, err)
+		}
+		if report.Result() != test.want {
+			t.Errorf("test %d: expected %s, got %s", i, test.want, report.Result())
+		}
+		if test.want == "safe" {
+			continue
+		}
+		f := report.Ranked()[0]
+		if f.Severity() != "high" || f.Operands[0].Uint64() != 4 || !reflect.DeepEqual(f.Labels(), []string{"calldata"}) {
+			t.Errorf("test %d: unexpected finding %s of %v from %v", i, f.Severity(), f.Operands, f.Labels())
+		}
+	}
+}
+
+func TestLoopBound(t *testing.T) {
+	// loopCode counts up to the bound pushed by bound, which takes 3 bytes.
+	loopCode := func(bound ...byte) []byte {
+		code := []byte{byte(vm.PUSH1), 0, byte(vm.JUMPDEST), byte(vm.DUP1)}
+		code = append(code, bound...)
+		return append(code,
+			byte(vm.GT), byte(vm.ISZERO), byte(vm.PUSH1), 18, byte(vm.JUMPI),
+			byte(vm.PUSH1), 1, byte(vm.ADD), byte(vm.PUSH1), 2, byte(vm.JUMP),
+			byte(vm.JUMPDEST), byte(vm.STOP),
+		)
+	}
+	tests := []struct {
+		code       []byte
+		want       string
+		iterations uint64
+	}{
+		{loopCode(byte(vm.PUSH1), 0, byte(vm.CALLDATALOAD)), "tainted loop bound", 3},
+		{loopCode(byte(vm.PUSH2), 0, 3), "safe", 0},
+	}
+	for i, test := range tests {
+		_, _, report, err := Execute(test.code, common.LeftPadBytes([]byte{3}, 32), nil)
+		if err != nil {
+			t.Fatalf("test %d: didn't expect error: %v", i, err)
+		}
+		if report.Result() != test.want {
+			t.Errorf("test %d: expected %s, got %s", i, test.want, report.Result())
+		}
+		if test.iterations == 0 {
+			continue
+		}
+		f := report.Findings[0]
+		if f.Pc != 11 || f.Iterations != test.iterations || f.GasUsed == 0 {
+			t.Errorf("test %d: unexpected finding at pc %d, %d iterations using %d gas", i, f.Pc, f.Iterations, f.GasUsed)
+		}
+	}
+}
+
+func TestCallInputTaint(t *testing.T) {
+	// callCode calls taintAddCode at 0x0a with the first memory word as input.
+	callCode := func(prepare ...byte) []byte {
+		return append(prepare,
+			byte(vm.PUSH1), 32,
+			byte(vm.PUSH1), 0,
+			byte(vm.PUSH1), 32,
+			byte(vm.PUSH1), 0,
+			byte(vm.PUSH1), 0,
+			byte(vm.PUSH1), 0x0a,
+			byte(vm.GAS),
+			byte(vm.CALL),
+			byte(vm.STOP),
+		)
+	}
+	tests := []struct {
+		code []byte
+		want string
+	}{
+		// constant argument: the callee's calldata is not attacker controlled
+		{callCode(byte(vm.PUSH1), 0, byte(vm.NOT), byte(vm.PUSH1), 0, byte(vm.MSTORE)), "safe"},
+		// forwarded calldata keeps its taint in the callee
+		{callCode(byte(vm.PUSH1), 32, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.CALLDATACOPY)), "overflow"},
+	}
+	for i, test := range tests {
+		state, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+		state.SetCode(common.HexToAddress("0x0a"), taintAddCode)
+		state.SetCode(common.HexToAddress("0x0b"), test.code)
+		// the success of the call is not checked, only look for overflows
+		cfg := &Config{State: state, EVMConfig: vm.Config{Detectors: []vm.Detector{vm.NewOverflowDetector()}}}
+		_, _, report, err := Call(common.HexToAddress("0x0b"), bytes.Repeat([]byte{0xff}, 32), cfg)
+		if err != nil {
+			t.Fatalf("test %d: didn't expect error: %v", i, err)
+		}
+		if report.Result() != test.want {
+			t.Errorf("test %d: expected %s, got %s", i, test.want, report.Result())
+		}
+	}
+}
+
+func TestSelectorTaint(t *testing.T) {
+	// doubleCode loads the calldata word at offset, applies cut to it, adds
+	// the result to itself and returns the sum.
+	doubleCode := func(offset byte, cut ...byte) []byte {
+		code := append([]byte{byte(vm.JUMPDEST), byte(vm.PUSH1), offset, byte(vm.CALLDATALOAD)}, cut...)
+		return append(code,
+			byte(vm.DUP1),
+			byte(vm.ADD),
+			byte(vm.PUSH1), 0,
+			byte(vm.MSTORE),
+			byte(vm.PUSH1), 32,
+			byte(vm.PUSH1), 0,
+			byte(vm.RETURN),
+		)
+	}
+	mask := func(n int) []byte {
+		return append([]byte{byte(vm.PUSH32)}, append(bytes.Repeat([]byte{0xff}, n), make([]byte, 32-n)...)...)
+	}
+	divisor := append([]byte{byte(vm.PUSH29), 0x01}, make([]byte, 28)...)
+
+	tests := []struct {
+		code []byte
+		want string
+	}{
+		{doubleCode(0, byte(vm.PUSH1), 0xe0, byte(vm.SHR)), "safe"},
+		{doubleCode(0, append(append(divisor, byte(vm.SWAP1), byte(vm.DIV), byte(vm.PUSH4), 0xff, 0xff, 0xff, 0xff), byte(vm.AND))...), "safe"},
+		{doubleCode(0, append(mask(4), byte(vm.AND))...), "safe"},
+		// the argument bytes of the first word stay tainted
+		{doubleCode(0, byte(vm.PUSH1), 0xd0, byte(vm.SHR)), "potential overflow"},
+		{doubleCode(0, append(mask(5), byte(vm.AND))...), "overflow"},
+		{doubleCode(0), "overflow"},
+		{doubleCode(4, byte(vm.PUSH1), 0xe0, byte(vm.SHR)), "potential overflow"},
+	}
+	for i, test := range tests {
+		_, _, report, err := Execute(test.code, bytes.Repeat([]byte{0xff}, 36), nil)
+		if err != nil {
+			t.Fatalf("test %d: didn't expect error: %v", i, err)
+		}
+		if report.Result() != test.want {
+			t.Errorf("test %d: expected %s, got %s", i, test.want, report.Result())
+		}
+	}
+}
+
 func BenchmarkCall(b *testing.B) {
 	var definition = `[{"constant":true,"inputs":[],"name":"seller","outputs":[{"name":"","type":"address"}],"type":"function"},{"constant":false,"inputs":[],"name":"abort","outputs":[],"type":"function"},{"constant":true,"inputs":[],"name":"value","outputs":[{"name":"","type":"uint256"}],"type":"function"},{"constant":false,"inputs":[],"name":"refund","outputs":[],"type":"function"},{"constant":true,"inputs":[],"name":"buyer","outputs":[{"name":"","type":"address"}],"type":"function"},{"constant":false,"inputs":[],"name":"confirmReceived","outputs":[],"type":"function"},{"constant":true,"inputs":[],"name":"state","outputs":[{"name":"","type":"uint8"}],"type":"function"},{"constant":false,"inputs":[],"name":"confirmPurchase","outputs":[],"type":"function"},{"inputs":[],"type":"constructor"},{"anonymous":false,"inputs":[],"name":"Aborted","type":"event"},{"anonymous":false,"inputs":[],"name":"PurchaseConfirmed","type":"event"},{"anonymous":false,"inputs":[],"name":"ItemReceived","type":"event"},{"anonymous":false,"inputs":[],"name":"Refunded","type":"event"}]`
 
@@ -148,3 +1196,119 @@ func BenchmarkCall(b *testing.B) {
 		}
 	}
 }
+
+// realworldTransaction is the transaction of a contract in taint-realworld.
+type realworldTransaction struct {
+	name        string
+	code, input []byte
+}
+
+// readRealworld reads the transactions of the scripts in taint-realworld.
+func readRealworld(tb testing.TB) []realworldTransaction {
+	scripts, err := filepath.Glob("../../../taint-realworld/*.sh")
+	if err != nil || len(scripts) == 0 {
+		tb.Skip("no real world contracts")
+	}
+	inputRe := regexp.MustCompile(`(?m)^input="([0-9a-fA-F]*)"`)
+	var txs []realworldTransaction
+	for _, script := range scripts {
+		name := strings.TrimSuffix(script, ".sh")
+		src, err := ioutil.ReadFile(script)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		hex, err := ioutil.ReadFile(name + ".bin-runtime")
+		if err != nil {
+			tb.Fatal(err)
+		}
+		txs = append(txs, realworldTransaction{
+			name:  filepath.Base(name),
+			code:  common.Hex2Bytes(strings.TrimSpace(string(hex))),
+			input: common.Hex2Bytes(inputRe.FindStringSubmatch(string(src))[1]),
+		})
+	}
+	return txs
+}
+
+// TestTaintRealworld checks the verdicts of the real world contracts against
+// the table of taint-realworld/README.md.
+func TestTaintRealworld(t *testing.T) {
+	readme, err := ioutil.ReadFile("../../../taint-realworld/README.md")
+	if err != nil {
+		t.Skip("no real world contracts")
+	}
+	// the verdict of every column of the table
+	columns := []string{"overflow", "protected overflow", "potential overflow", "potential overflow", "safe"}
+	verdicts := make(map[string]string)
+	for _, line := range strings.Split(string(readme), "\n") {
+		cells := strings.Split(line, "|")
+		if len(cells) < len(columns)+2 {
+			continue
+		}
+		for i, column := range columns {
+			if strings.TrimSpace(cells[i+2]) == "√" {
+				verdicts[strings.TrimSpace(cells[1])] = column
+			}
+		}
+	}
+	for _, tx := range readRealworld(t) {
+		want, ok := verdicts[tx.name]
+		if !ok {
+			t.Errorf("%s: no verdict in the README", tx.name)
+			continue
+		}
+		if _, _, report, _ := Execute(tx.code, tx.input, nil); report.Result() != want {
+			t.Errorf("%s: expected %s, got %s", tx.name, want, report.Result())
+		}
+	}
+}
+
+// TestTaintSamples checks the verdicts of the sample contracts against the
+// comments of taint_contracts/cmd.sh.
+func TestTaintSamples(t *testing.T) {
+	src, err := ioutil.ReadFile("../../../taint_contracts/cmd.sh")
+	if err != nil {
+		t.Skip("no sample contracts")
+	}
+	sampleRe := regexp.MustCompile(`(?m)^# (\w+) ([a-z ]+) \((\w+)\)\n.* --codefile (\S+) --input ([0-9a-fA-F]+)`)
+	samples := sampleRe.FindAllStringSubmatch(string(src), -1)
+	if len(samples) == 0 {
+		t.Fatal("no samples in cmd.sh")
+	}
+	for _, sample := range samples {
+		hex, err := ioutil.ReadFile("../../../" + sample[4])
+		if err != nil {
+			t.Fatal(err)
+		}
+		code := common.Hex2Bytes(strings.TrimSpace(string(hex)))
+		if _, _, report, _ := Execute(code, common.Hex2Bytes(sample[5]), nil); report.Result() != sample[2] {
+			t.Errorf("%s %s: expected %s, got %s", sample[1], sample[3], sample[2], report.Result())
+		}
+	}
+}
+
+// BenchmarkTaintRealworld runs the transactions of the real world contracts
+// in taint-realworld, whose taint memory holds calldata copied around:
+//
+//	go test -run NONE -bench TaintRealworld ./core/vm/runtime
+//
+// Keeping the taint memory as spans of tainted bytes instead of a taint per
+// byte took a transaction from 990 to 834 µs for SMT, 541 to 417 µs for
+// RedEnvelope, 538 to 455 µs for BecToken and 308 to 257 µs for Lizun. The
+// darx transaction, which fails at the guard of its overflow, went from 210
+// to 229 µs.
+func BenchmarkTaintRealworld(b *testing.B) {
+	// transactions failing at the guard of their overflow
+	fails := map[string]bool{"darx": true}
+	for _, tx := range readRealworld(b) {
+		tx := tx
+		b.Run(tx.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, _, _, err := Execute(tx.code, tx.input, nil); (err != nil) != fails[tx.name] {
+					b.Fatal("unexpected result, error:", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+// Author: Jianbo-Gao
+// Detecting access control flaws: tx.origin checks and tainted targets.
+
+package vm
+
+// A contract authorizing the sender with
+//
+//	require(tx.origin == owner);
+//
+// lets any contract the owner is lured into calling act on their behalf.
+// The result of an EQ on ORIGIN is labelled with ORIGIN_EQ_FLAG, a JUMPI on
+// a condition carrying the label is reported. Comparing tx.origin with
+// msg.sender only tells contracts from accounts, it is not reported.
+//
+// Calling, delegating to or self-destructing in favour of an address taken
+// from calldata lets anyone pick the code that runs with the contract's
+// value, storage or balance. Such operations are reported as tainted
+// targets.
+
+// originAuthDetector records JUMPIs deciding on a comparison of tx.origin.
+type originAuthDetector struct{}
+
+// NewOriginAuthDetector returns the tx.origin authorization detector.
+func NewOriginAuthDetector() Detector {
+	return originAuthDetector{}
+}
+
+func (originAuthDetector) CaptureOp(step *DetectorStep) DetectorResult {
+	switch step.Op {
+	case EQ:
+		tx, ty := step.TaintStack.Back(0), step.TaintStack.Back(1)
+		if (tx|ty)&ORIGIN_FLAG == 0 || (tx|ty)&CALLER_FLAG > 0 || tx&ty&ORIGIN_FLAG > 0 {
+			return nil
+		}
+		return func(step *DetectorStep) {
+			step.TaintStack.Set(0, step.TaintStack.Back(0)|ORIGIN_EQ_FLAG)
+		}
+
+	case JUMPI:
+		tc := step.TaintStack.Back(1)
+		if tc&ORIGIN_EQ_FLAG == 0 || ORIGIN_AUTH_FLAG == SAFE_FLAG || step.Report().recorded(step.Contract, step.Pc, ORIGIN_AUTH_FLAG) {
+			return nil
+		}
+		step.Record(ORIGIN_AUTH_FLAG, []int{step.TaintStack.Back(0), tc}, nil, step.Operand(0), step.Operand(1))
+	}
+	return nil
+}
+
+// taintedTargetDetector records calls and self-destructs to addresses taken
+// from calldata.
+type taintedTargetDetector struct{}
+
+// NewTaintedTargetDetector returns the tainted call target detector.
+func NewTaintedTargetDetector() Detector {
+	return taintedTargetDetector{}
+}
+
+func (taintedTargetDetector) CaptureOp(step *DetectorStep) DetectorResult {
+	// position of the address on the stack
+	var n int
+	switch step.Op {
+	case CALL, CALLCODE, DELEGATECALL:
+		n = 1
+	case SELFDESTRUCT:
+		n = 0
+	default:
+		return nil
+	}
+	ta := step.TaintStack.Back(n)
+	if ta&CALLDATA_FLAG == 0 || TAINTED_TARGET_FLAG == SAFE_FLAG || step.Report().recorded(step.Contract, step.Pc, TAINTED_TARGET_FLAG) {
+		return nil
+	}
+	step.Record(TAINTED_TARGET_FLAG, []int{ta}, nil, step.Operand(n))
+	return nil
+}
+
+// recorded reports whether the operation at pc of the code of contract
+// already has a finding with flag, e.g. in a loop.
+func (r *TaintReport) recorded(contract *Contract, pc uint64, flag int) bool {
+	for _, f := range r.Findings {
+		if f.Pc == pc && f.CodeHash == contract.CodeHash && f.Address == contract.Address() && f.Flag&flag > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,157 @@
+// Author: Jianbo-Gao
+// Mapping calldata taint to the ABI parameters of the called method.
+
+package vm
+
+import (
+	"fmt"
+	"math/big"
+)
+
+var big31 = big.NewInt(31)
+
+// TaintArg describes an ABI parameter of the called method, in the order of
+// the method signature.
+type TaintArg struct {
+	Name    string // parameter name, may be empty
+	Words   int    // head words of a static parameter, 0 means 1
+	Dynamic bool   // the head holds the offset of a length-prefixed tail
+	Packed  bool   // the tail length counts bytes instead of words
+}
+
+// TaintArgRef is a calldata word an operand is derived from.
+type TaintArgRef struct {
+	Word  int    `json:"word"`  // word index after the function selector
+	Label string `json:"label"` // e.g. "arg1", "length of arg0", "arg0[1]"
+}
+
+// newCalldataTaint returns the taint of calldata coming from outside the
+// EVM: every byte is untrusted and labelled with the word it belongs to.
+func newCalldataTaint(input []byte) []int {
+	t_value := make([]int, len(input))
+	for i := range t_value {
+		t_value[i] = CALLDATA_FLAG
+		if i >= 4 {
+			t_value[i] |= calldataWordFlag((i - 4) / 32)
+		}
+	}
+	return t_value
+}
+
+// argLayout labels the words of the top-level calldata.
+type argLayout struct {
+	labels []string // label of every word after the function selector
+}
+
+// newArgLayout lays out the arguments in input. If args is nil, the layout
+// is inferred from the calldata itself.
+func newArgLayout(input []byte, args []TaintArg) *argLayout {
+	l := &argLayout{}
+	if len(input) <= 4 {
+		return l
+	}
+	l.labels = make([]string, (len(input)-4+31)/32)
+	if args == nil {
+		args = inferArgs(input)
+	}
+
+	head := 0
+	for i, arg := range args {
+		name := fmt.Sprintf("arg%d", i)
+		suffix := ""
+		if arg.Name != "" {
+			suffix = fmt.Sprintf(" (%s)", arg.Name)
+		}
+		if !arg.Dynamic {
+			if arg.Words <= 1 {
+				l.set(head, name+suffix)
+				head++
+				continue
+			}
+			for j := 0; j < arg.Words; j++ {
+				l.set(head+j, fmt.Sprintf("%s[%d]%s", name, j, suffix))
+			}
+			head += arg.Words
+			continue
+		}
+		l.set(head, "offset of "+name+suffix)
+		if start, ok := wordOffset(input, head); ok {
+			l.set(start, "length of "+name+suffix)
+			n := argWord(input, start)
+			if arg.Packed {
+				n.Add(n, big31).Div(n, big32)
+			}
+			for j := 0; start+1+j < len(l.labels) && n.Cmp(big.NewInt(int64(j))) > 0; j++ {
+				l.set(start+1+j, fmt.Sprintf("%s[%d]%s", name, j, suffix))
+			}
+		}
+		head++
+	}
+	return l
+}
+
+func (l *argLayout) set(word int, label string) {
+	if word < len(l.labels) && l.labels[word] == "" {
+		l.labels[word] = label
+	}
+}
+
+// refs returns the calldata words the taint is derived from.
+func (l *argLayout) refs(taint int) []TaintArgRef {
+	refs := []TaintArgRef{}
+	for k := 0; k < len(l.labels); k++ {
+		if taint&calldataWordFlag(k) == 0 {
+			continue
+		}
+		label := l.labels[k]
+		if label == "" {
+			label = fmt.Sprintf("calldata word %d", k)
+		}
+		refs = append(refs, TaintArgRef{Word: k, Label: label})
+	}
+	return refs
+}
+
+// inferArgs guesses the parameters from the standard ABI encoding: a head
+// word pointing into the calldata at a length that fits the remaining data
+// is taken to be the offset of a dynamic parameter, whose tail ends the head.
+func inferArgs(input []byte) []TaintArg {
+	var (
+		args  []TaintArg
+		words = (len(input) - 4 + 31) / 32
+		end   = words
+	)
+	for k := 0; k < end; k++ {
+		arg := TaintArg{}
+		if start, ok := wordOffset(input, k); ok && start > k {
+			n := argWord(input, start)
+			rest := big.NewInt(int64(words - start - 1))
+			if n.Cmp(rest) <= 0 {
+				arg.Dynamic = true
+			} else if n.Add(n, big31).Div(n, big32).Cmp(rest) <= 0 {
+				arg.Dynamic, arg.Packed = true, true
+			}
+			if arg.Dynamic && start < end {
+				end = start
+			}
+		}
+		args = append(args, arg)
+	}
+	return args
+}
+
+// argWord returns the k-th word after the function selector.
+func argWord(input []byte, k int) *big.Int {
+	return new(big.Int).SetBytes(getDataBig(input, big.NewInt(int64(4+32*k)), big32))
+}
+
+// wordOffset interprets the k-th word as a tail offset and returns the index
+// of the word it points to.
+func wordOffset(input []byte, k int) (int, bool) {
+	v := argWord(input, k)
+	words := (len(input) - 4 + 31) / 32
+	if v.Cmp(big.NewInt(int64(32*words))) >= 0 || v.Uint64()%32 != 0 {
+		return 0, false
+	}
+	return int(v.Uint64() / 32), true
+}
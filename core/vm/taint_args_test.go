@@ -0,0 +1,56 @@
+// Author: Jianbo-Gao
+// Tests for mapping calldata taint to ABI parameters.
+
+package vm
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// batchTransfer(address[] _receivers, uint256 _value) with two receivers
+var batchTransferInput = common.Hex2Bytes("83f12fec" +
+	"0000000000000000000000000000000000000000000000000000000000000040" +
+	"8000000000000000000000000000000000000000000000000000000000000000" +
+	"0000000000000000000000000000000000000000000000000000000000000002" +
+	"000000000000000000000000b4d30cac5124b46c2df0cf3e3e1be05f42119033" +
+	"0000000000000000000000000e823ffe018727585eaf5bc769fa80472f76c3d7")
+
+func TestArgLayout(t *testing.T) {
+	tests := []struct {
+		args []TaintArg
+		want []string
+	}{
+		{nil, []string{"offset of arg0", "arg1", "length of arg0", "arg0[0]", "arg0[1]"}},
+		{
+			[]TaintArg{{Name: "_receivers", Dynamic: true}, {Name: "_value"}},
+			[]string{"offset of arg0 (_receivers)", "arg1 (_value)", "length of arg0 (_receivers)", "arg0[0] (_receivers)", "arg0[1] (_receivers)"},
+		},
+		{
+			[]TaintArg{{Words: 2}, {}},
+			[]string{"arg0[0]", "arg0[1]", "arg1", "calldata word 3", "calldata word 4"},
+		},
+	}
+	for i, test := range tests {
+		layout := newArgLayout(batchTransferInput, test.args)
+		var labels []string
+		for _, ref := range layout.refs(CALLDATA_WORD_FLAGS) {
+			labels = append(labels, ref.Label)
+		}
+		if !reflect.DeepEqual(labels, test.want) {
+			t.Errorf("test %d: expected %v, got %v", i, test.want, labels)
+		}
+	}
+}
+
+func TestCalldataTaint(t *testing.T) {
+	taint := newCalldataTaint(batchTransferInput)
+	if taint[3] != CALLDATA_FLAG {
+		t.Errorf("expected selector byte to be labelled calldata only, got %x", taint[3])
+	}
+	if want := CALLDATA_FLAG | calldataWordFlag(1); taint[4+32] != want || taint[4+63] != want {
+		t.Errorf("expected word 1 to be labelled %x, got %x and %x", want, taint[4+32], taint[4+63])
+	}
+}
@@ -0,0 +1,78 @@
+// Author: Jianbo-Gao
+// Plugging detectors into the taint engine.
+
+package vm
+
+import (
+	"math/big"
+)
+
+// Detector is notified of every operation the interpreter executes. The
+// opcode handlers only propagate taint, detectors look at the values and
+// their taint to record findings in the report of the EVM.
+//
+// Detectors are shared by every EVM using the same Config, any state of an
+// execution belongs in the returned DetectorResult.
+type Detector interface {
+	// CaptureOp is called before the operation of the step is executed,
+	// its operands are on top of the stacks. A non-nil result is called
+	// once the operation has been executed successfully.
+	CaptureOp(step *DetectorStep) DetectorResult
+}
+
+// DetectorResult is called with the step after its operation has been
+// executed, the result, if any, is on top of the stacks.
+type DetectorResult func(step *DetectorStep)
+
+// DetectorStep describes the operation being executed.
+type DetectorStep struct {
+	EVM         *EVM
+	Contract    *Contract
+	Pc          uint64
+	Op          OpCode
+	Stack       *Stack
+	Memory      *Memory
+	TaintStack  *TaintStack
+	TaintMemory *TaintMemory
+}
+
+// Report returns the report findings are recorded in.
+func (step *DetectorStep) Report() *TaintReport {
+	return step.EVM.taintReport
+}
+
+// Operand returns a copy of the n'th item of the stack, safe to keep after
+// the operation is executed.
+func (step *DetectorStep) Operand(n int) *big.Int {
+	return new(big.Int).Set(step.Stack.Back(n))
+}
+
+// Record adds a finding for the operation of the step, see record.
+func (step *DetectorStep) Record(flag int, taints []int, result *big.Int, operands ...*big.Int) int {
+	return step.EVM.taintReport.record(step.EVM, step.Contract, step.Pc, step.Op, flag, taints, result, operands...)
+}
+
+// DefaultDetectors returns the detectors used when Config.Detectors is nil.
+func DefaultDetectors() []Detector {
+	return []Detector{
+		NewOverflowDetector(),
+		NewReentrancyDetector(),
+		NewUncheckedCallDetector(),
+		NewOriginAuthDetector(),
+		NewTaintedTargetDetector(),
+		NewTaintedJumpDetector(),
+		NewLoopBoundDetector(),
+	}
+}
+
+// captureOp notifies every detector of the step and returns the results to
+// call after the operation, results is reused.
+func captureOp(detectors []Detector, step *DetectorStep, results []DetectorResult) []DetectorResult {
+	results = results[:0]
+	for _, d := range detectors {
+		if res := d.CaptureOp(step); res != nil {
+			results = append(results, res)
+		}
+	}
+	return results
+}
@@ -8,5 +8,151 @@ const CALLDATA_FLAG int = 1
 const POTENTIAL_OVERFLOW_FLAG int = 1 << 1
 const PROTECTED_OVERFLOW_FLAG int = 1 << 2
 const OVERFLOW_FLAG int = 1 << 3
+const SELECTOR_WORD_FLAG int = 1 << 4
 
-var global_taint_flag = SAFE_FLAG
+// Source labels record which untrusted input a value is derived from.
+const CALLVALUE_FLAG int = 1 << 5
+const CALLER_FLAG int = 1 << 6
+const ORIGIN_FLAG int = 1 << 7
+const TIMESTAMP_FLAG int = 1 << 8
+const NUMBER_FLAG int = 1 << 9
+const BLOCKHASH_FLAG int = 1 << 10
+const COINBASE_FLAG int = 1 << 11
+const RETURNDATA_FLAG int = 1 << 12
+const STORAGE_FLAG int = 1 << 13
+
+// Guard labels tie an overflowed result to its finding until the result
+// reaches a guard, see taint_guard.go.
+const GUARD_SHIFT uint = 14
+const GUARD_LABELS int = 4
+const GUARD_FLAGS int = (1<<uint(GUARD_LABELS) - 1) << GUARD_SHIFT
+
+// Signed integers: SIGNED_FLAG marks a value known to be signed, see
+// taint_signed.go.
+const SIGNED_FLAG int = 1 << 18
+const SIGNED_OVERFLOW_FLAG int = 1 << 19
+const PROTECTED_SIGNED_OVERFLOW_FLAG int = 1 << 20
+
+// Underflows of SUB are told apart from overflows, they have their own
+// verdicts.
+const UNDERFLOW_FLAG int = 1 << 21
+const PROTECTED_UNDERFLOW_FLAG int = 1 << 22
+const POTENTIAL_UNDERFLOW_FLAG int = 1 << 23
+
+// ARITHMETIC_FLAG marks the result of tainted arithmetic until it is cut
+// down to a narrower type, see taint_narrow.go.
+const ARITHMETIC_FLAG int = 1 << 24
+
+// OVERFLOWED_FLAG follows the wrapped result of an overflow to the sinks it
+// reaches, see taint_sink.go.
+const OVERFLOWED_FLAG int = 1 << 25
+
+// IMPLICIT_FLAG marks a value that depends on a tainted branch, see
+// taint_implicit.go.
+const IMPLICIT_FLAG int = 1 << 26
+
+// REENTRANCY_FLAG marks a call open to reentrancy, see taint_reentrancy.go.
+const REENTRANCY_FLAG int = 1 << 27
+
+// CALL_SUCCESS_FLAG is the first of the CALL_LABELS labels of the success
+// flag of a call until it is checked, UNCHECKED_CALL_FLAG marks a call whose
+// success was never checked, see taint_unchecked.go.
+const CALL_SUCCESS_FLAG int = 1 << 28
+const CALL_LABELS int = 4
+const UNCHECKED_CALL_FLAG int = 1 << 29
+
+// ORIGIN_EQ_FLAG labels the comparison of tx.origin with an address other
+// than the caller, ORIGIN_AUTH_FLAG marks such a comparison deciding a
+// JUMPI, see taint_access.go. ORIGIN_AUTH_FLAG needs a 64-bit int, on
+// 32-bit platforms it is never raised.
+const ORIGIN_EQ_FLAG int = 1 << 30
+
+var ORIGIN_AUTH_FLAG int = wideFlag(31)
+
+const SOURCE_FLAGS int = CALLDATA_FLAG | CALLVALUE_FLAG | CALLER_FLAG | ORIGIN_FLAG | TIMESTAMP_FLAG | NUMBER_FLAG | BLOCKHASH_FLAG | COINBASE_FLAG | RETURNDATA_FLAG | STORAGE_FLAG
+
+// sourceNames lists the source labels in the order they are reported.
+var sourceNames = []struct {
+	flag int
+	name string
+}{
+	{CALLDATA_FLAG, "calldata"},
+	{CALLVALUE_FLAG, "callvalue"},
+	{CALLER_FLAG, "caller"},
+	{ORIGIN_FLAG, "origin"},
+	{TIMESTAMP_FLAG, "timestamp"},
+	{NUMBER_FLAG, "number"},
+	{BLOCKHASH_FLAG, "blockhash"},
+	{COINBASE_FLAG, "coinbase"},
+	{RETURNDATA_FLAG, "returndata"},
+	{STORAGE_FLAG, "storage"},
+}
+
+// taintSources returns the names of the source labels set in flag.
+func taintSources(flag int) []string {
+	sources := []string{}
+	for _, s := range sourceNames {
+		if flag&s.flag > 0 {
+			sources = append(sources, s.name)
+		}
+	}
+	return sources
+}
+
+// Calldata word labels: the k-th 32 byte word after the function selector
+// is labelled with bit CALLDATA_WORD_SHIFT+k, the last label stands for all
+// later words. They need a 64-bit int, on 32-bit platforms no word labels
+// are recorded.
+const CALLDATA_WORD_SHIFT uint = 32
+const CALLDATA_WORDS int = 25
+
+var CALLDATA_WORD_FLAGS int = calldataWordFlag(0) * (1<<uint(CALLDATA_WORDS) - 1)
+
+// calldataWordFlag returns the label of the k-th calldata word.
+func calldataWordFlag(k int) int {
+	if k >= CALLDATA_WORDS {
+		k = CALLDATA_WORDS - 1
+	}
+	return 1 << (CALLDATA_WORD_SHIFT + uint(k))
+}
+
+// Verdicts above the calldata word labels need a 64-bit int as well, on
+// 32-bit platforms they are never raised.
+var TAINTED_TARGET_FLAG int = wideFlag(CALLDATA_WORD_SHIFT + uint(CALLDATA_WORDS))
+var TAINTED_JUMP_FLAG int = wideFlag(CALLDATA_WORD_SHIFT + uint(CALLDATA_WORDS) + 1)
+var LOOP_BOUND_FLAG int = wideFlag(CALLDATA_WORD_SHIFT + uint(CALLDATA_WORDS) + 2)
+
+// The call success labels after the first follow the verdicts, on 32-bit
+// platforms there is only CALL_SUCCESS_FLAG.
+const CALL_SUCCESS_SHIFT uint = CALLDATA_WORD_SHIFT + uint(CALLDATA_WORDS) + 2
+
+var CALL_SUCCESS_FLAGS int = CALL_SUCCESS_FLAG | callSuccessFlag(1) | callSuccessFlag(2) | callSuccessFlag(3)
+
+// callSuccessFlag returns the n-th call success label, zero if there is no
+// such label.
+func callSuccessFlag(n int) int {
+	if n == 0 {
+		return CALL_SUCCESS_FLAG
+	}
+	return wideFlag(CALL_SUCCESS_SHIFT + uint(n))
+}
+
+// VERDICT_FLAGS are the classifications of findings, see taintClass.
+var VERDICT_FLAGS int = POTENTIAL_OVERFLOW_FLAG | PROTECTED_OVERFLOW_FLAG | OVERFLOW_FLAG |
+	UNDERFLOW_FLAG | PROTECTED_UNDERFLOW_FLAG | POTENTIAL_UNDERFLOW_FLAG |
+	SIGNED_OVERFLOW_FLAG | PROTECTED_SIGNED_OVERFLOW_FLAG |
+	REENTRANCY_FLAG | UNCHECKED_CALL_FLAG | ORIGIN_AUTH_FLAG |
+	TAINTED_TARGET_FLAG | TAINTED_JUMP_FLAG | LOOP_BOUND_FLAG
+
+// REPORT_FLAGS only have a meaning within the report of one execution, they
+// are not kept in storage.
+var REPORT_FLAGS int = GUARD_FLAGS | OVERFLOWED_FLAG | CALL_SUCCESS_FLAGS | ORIGIN_EQ_FLAG | VERDICT_FLAGS
+
+// wideFlag returns the flag of bit n, zero if int has no such bit below its
+// sign bit.
+func wideFlag(n uint) int {
+	if flag := 1 << n; flag > 0 {
+		return flag
+	}
+	return SAFE_FLAG
+}
@@ -0,0 +1,103 @@
+// Author: Jianbo-Gao
+// Deciding whether an overflow is protected by a guard.
+
+package vm
+
+// An overflow is protected when its result, or a comparison derived from
+// it, decides a JUMPI and the branch taken on the overflowed values fails
+// the frame before any state is modified: a REVERT, an INVALID or failed
+// assert, or a throw through a bad jump. This recognizes SafeMath from any
+// compiler version as well as inlined checks and custom require guards,
+// including shift round trips such as require((x << n) >> n == x).
+//
+// Every overflow finding labels its result with a guard flag. A JUMPI on a
+// condition carrying the label marks the finding as checked; a state
+// modification afterwards makes the overflow final, failing the checking
+// frame first makes it protected. So does returning normally from the
+// checking frame with the result neither handed to a sink nor returned,
+// e.g. if (a + b < a) return false. A checked overflow whose frame returns
+// its result goes back to pending. Overflows that never reach a JUMPI stay
+// unprotected.
+//
+// There are only GUARD_LABELS labels. A new overflow takes a free label,
+// else the label of the oldest finding whose verdict is final, else the
+// oldest label. The finding losing its label keeps the verdict and sink it
+// has so far.
+
+// Guard states of an overflow finding.
+const (
+	guardNone    = iota // not an overflow, or the verdict is final
+	guardPending        // the result has not reached a JUMPI yet
+	guardChecked        // the result decided a JUMPI, waiting for side effects
+)
+
+// guardFlag returns the n-th guard label.
+func guardFlag(n int) int {
+	return 1 << (GUARD_SHIFT + uint(n))
+}
+
+// guard is called for a JUMPI at the given depth on a condition with the
+// given taint.
+func (r *TaintReport) guard(depth int, cond int) {
+	if cond&GUARD_FLAGS == 0 {
+		return
+	}
+	for _, findings := range [][]*Finding{r.Findings, r.candidates} {
+		for _, f := range findings {
+			if f.guardState == guardPending && cond&f.guard > 0 {
+				f.guardState = guardChecked
+				f.guardDepth = depth
+			}
+		}
+	}
+}
+
+// effect is called before every state modifying operation.
+func (r *TaintReport) effect() {
+	for _, findings := range [][]*Finding{r.Findings, r.candidates} {
+		for _, f := range findings {
+			if f.guardState == guardChecked {
+				f.guardState = guardNone
+			}
+		}
+	}
+}
+
+// leave is called when the frame at the given depth returns data with the
+// given taint. If it failed, with an error or a revert, checked overflows of
+// the frame had no effect and are protected, as are those whose result was
+// thrown away.
+func (r *TaintReport) leave(depth int, failed bool, returned int) {
+	protected := false
+	for _, findings := range [][]*Finding{r.Findings, r.candidates} {
+		for _, f := range findings {
+			if f.guardState != guardChecked || f.guardDepth < depth {
+				continue
+			}
+			if !failed && (f.sink > sinkDropped || returned&f.guard > 0) {
+				f.guardState = guardPending
+				continue
+			}
+			f.Flag = protectFlag(f.Flag)
+			f.guardState = guardNone
+			protected = true
+		}
+	}
+	if protected {
+		r.update()
+	}
+}
+
+// protectFlag turns an overflow or underflow verdict into the protected one.
+func protectFlag(flag int) int {
+	if flag&OVERFLOW_FLAG > 0 {
+		flag = flag&^OVERFLOW_FLAG | PROTECTED_OVERFLOW_FLAG
+	}
+	if flag&UNDERFLOW_FLAG > 0 {
+		flag = flag&^UNDERFLOW_FLAG | PROTECTED_UNDERFLOW_FLAG
+	}
+	if flag&SIGNED_OVERFLOW_FLAG > 0 {
+		flag = flag&^SIGNED_OVERFLOW_FLAG | PROTECTED_SIGNED_OVERFLOW_FLAG
+	}
+	return flag
+}
@@ -0,0 +1,446 @@
+// Author: Jianbo-Gao
+// Propagating taint through control dependence on tainted branches.
+
+package vm
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// With Config.TaintImplicitFlow, a JUMPI on a tainted condition opens a
+// control region that lasts until execution reaches the immediate
+// post-dominator of the JUMPI, the point where both branches join again.
+// Every value pushed inside the region, e.g. the constant in
+//
+//	if (x > 10) amount = 1000;
+//
+// carries the sources of the condition and IMPLICIT_FLAG. Values copied to
+// memory or storage keep the taint they have on the stack.
+//
+// Post-dominators are computed statically on the basic blocks of the code.
+// Jumps to a pushed constant are resolved, other jumps, like returns from
+// internal functions, are taken to leave the code. Blocks ending in REVERT
+// or INVALID never join the normal exit, so a require() does not open a
+// region over the rest of the code. A JUMPI without a join keeps its region
+// until the frame returns.
+
+// controlFlow is the static control flow analysis of a contract code.
+type controlFlow struct {
+	joins map[uint64]int64    // pc of a JUMPI -> pc of its join, -1 if none
+	loops map[uint64][]uint64 // pc of a back edge -> JUMPIs of its loop
+}
+
+// cfgBlock is a basic block of the code.
+type cfgBlock struct {
+	start, last uint64     // pc of the first and the last instruction
+	succs       []int      // successor blocks, exit is len(blocks)
+	carried     []*big.Int // constants pushed since the previous JUMP, other than jump targets
+	returns     []int      // blocks an internal call made by the block returns to
+}
+
+// newControlFlow splits code into basic blocks and computes the join of
+// every JUMPI and the loops of the code.
+func newControlFlow(code []byte) *controlFlow {
+	var (
+		blocks    []*cfgBlock
+		blockAt   = make(map[uint64]int)
+		jumpdests = make(map[uint64]bool)
+		pushed    = make(map[uint64]*big.Int) // pc of a jump -> constant pushed before it
+		cur       *cfgBlock
+		prevPush  *big.Int
+		carried   []*big.Int
+	)
+	for pc := uint64(0); pc < uint64(len(code)); pc++ {
+		op := OpCode(code[pc])
+		if cur == nil || op == JUMPDEST {
+			cur = &cfgBlock{start: pc}
+			blockAt[pc] = len(blocks)
+			blocks = append(blocks, cur)
+		}
+		cur.last = pc
+		if op == JUMPDEST {
+			jumpdests[pc] = true
+		}
+		if (op == JUMP || op == JUMPI) && prevPush != nil {
+			pushed[pc] = prevPush
+			carried = carried[:len(carried)-1]
+		}
+		if op == JUMP {
+			cur.carried, carried = carried, nil
+		}
+		prevPush = nil
+		if op.IsPush() {
+			n := uint64(op - PUSH1 + 1)
+			prevPush = new(big.Int).SetBytes(getDataBig(code, new(big.Int).SetUint64(pc+1), new(big.Int).SetUint64(n)))
+			carried = append(carried, prevPush)
+			pc += n
+		}
+		if op == JUMP || op == JUMPI || endsBlock(op) {
+			cur = nil
+		}
+	}
+
+	exit := len(blocks)
+	target := func(pc uint64) (int, bool) {
+		dest, ok := pushed[pc]
+		if !ok || !dest.IsUint64() {
+			return exit, true
+		}
+		if !jumpdests[dest.Uint64()] {
+			return 0, false
+		}
+		return blockAt[dest.Uint64()], true
+	}
+	for i, b := range blocks {
+		next := exit
+		if i+1 < len(blocks) {
+			next = i + 1
+		}
+		switch op := OpCode(code[b.last]); {
+		case op == JUMP:
+			if t, ok := target(b.last); ok {
+				b.succs = append(b.succs, t)
+			}
+			// an internal call pushes the address it returns to before
+			// its arguments and the function it jumps to
+			for _, dest := range b.carried {
+				if dest.IsUint64() && jumpdests[dest.Uint64()] {
+					b.returns = append(b.returns, blockAt[dest.Uint64()])
+				}
+			}
+		case op == JUMPI:
+			b.succs = append(b.succs, next)
+			if t, ok := target(b.last); ok && t != next {
+				b.succs = append(b.succs, t)
+			}
+		case op == STOP || op == RETURN || op == SELFDESTRUCT:
+			b.succs = append(b.succs, exit)
+		case endsBlock(op):
+			// REVERT and undefined opcodes never reach the exit
+		default:
+			b.succs = append(b.succs, next)
+		}
+	}
+
+	ipdom := postDominators(blocks)
+	c := &controlFlow{joins: make(map[uint64]int64), loops: loops(code, blocks)}
+	for i, b := range blocks {
+		if OpCode(code[b.last]) != JUMPI {
+			continue
+		}
+		if d := ipdom[i]; d < 0 || d == exit {
+			c.joins[b.last] = -1
+		} else {
+			c.joins[b.last] = int64(blocks[d].start)
+		}
+	}
+	return c
+}
+
+// endsBlock reports whether op ends a basic block other than by a jump.
+func endsBlock(op OpCode) bool {
+	switch op {
+	case STOP, RETURN, REVERT, SELFDESTRUCT:
+		return true
+	}
+	// undefined opcodes, like the designated invalid 0xfe
+	return opCodeToString[op] == ""
+}
+
+// postDominators returns the immediate post-dominator of every block, the
+// exit is len(blocks). Blocks that never reach the exit get -1.
+func postDominators(blocks []*cfgBlock) []int {
+	exit := len(blocks)
+	preds := make([][]int, exit+1)
+	for i, b := range blocks {
+		for _, s := range b.succs {
+			preds[s] = append(preds[s], i)
+		}
+	}
+	return dominators(preds, exit)[:exit]
+}
+
+// dominators returns the immediate dominator of every node of the graph
+// given by the successors of each node, as seen from root. Nodes that are
+// not reachable from root get -1. It is the algorithm of Cooper, Harvey and
+// Kennedy.
+func dominators(succs [][]int, root int) []int {
+	n := len(succs)
+	preds := make([][]int, n)
+	for i, ss := range succs {
+		for _, s := range ss {
+			preds[s] = append(preds[s], i)
+		}
+	}
+
+	// postorder from the root
+	var (
+		order   []int
+		number  = make([]int, n)
+		visited = make([]bool, n)
+	)
+	for i := range number {
+		number[i] = -1
+	}
+	type frame struct{ node, next int }
+	stack := []frame{{root, 0}}
+	visited[root] = true
+	for len(stack) > 0 {
+		top := &stack[len(stack)-1]
+		if top.next < len(succs[top.node]) {
+			s := succs[top.node][top.next]
+			top.next++
+			if !visited[s] {
+				visited[s] = true
+				stack = append(stack, frame{s, 0})
+			}
+			continue
+		}
+		number[top.node] = len(order)
+		order = append(order, top.node)
+		stack = stack[:len(stack)-1]
+	}
+
+	idom := make([]int, n)
+	for i := range idom {
+		idom[i] = -1
+	}
+	idom[root] = root
+	intersect := func(a, b int) int {
+		for a != b {
+			for number[a] < number[b] {
+				a = idom[a]
+			}
+			for number[b] < number[a] {
+				b = idom[b]
+			}
+		}
+		return a
+	}
+	for changed := true; changed; {
+		changed = false
+		for k := len(order) - 2; k >= 0; k-- {
+			v := order[k]
+			d := -1
+			for _, p := range preds[v] {
+				if idom[p] < 0 {
+					continue
+				}
+				if d < 0 {
+					d = p
+				} else {
+					d = intersect(p, d)
+				}
+			}
+			if d != idom[v] {
+				idom[v] = d
+				changed = true
+			}
+		}
+	}
+	return idom
+}
+
+// loops returns the JUMPIs of the natural loop closed by every back edge,
+// a jump to a block dominating the jump. Returns from internal functions
+// are not resolved, they never close a loop; an internal call is taken to
+// continue at its return address instead.
+func loops(code []byte, blocks []*cfgBlock) map[uint64][]uint64 {
+	exit := len(blocks)
+	succs := make([][]int, exit+1)
+	preds := make([][]int, exit+1)
+	for i, b := range blocks {
+		succs[i] = append(append([]int{}, b.succs...), b.returns...)
+	}
+	// internal functions entered through an unresolved jump are taken to be
+	// called from the entry, the first JUMPDEST not reached yet is taken as
+	// the entry of a function
+	if exit > 0 {
+		reached := make([]bool, exit+1)
+		visit := func(root int) {
+			work := []int{root}
+			reached[root] = true
+			for len(work) > 0 {
+				v := work[len(work)-1]
+				work = work[:len(work)-1]
+				for _, s := range succs[v] {
+					if !reached[s] {
+						reached[s] = true
+						work = append(work, s)
+					}
+				}
+			}
+		}
+		visit(0)
+		for i, b := range blocks {
+			if !reached[i] && OpCode(code[b.start]) == JUMPDEST {
+				succs[0] = append(succs[0], i)
+				visit(i)
+			}
+		}
+	}
+	for i := range succs {
+		for _, s := range succs[i] {
+			preds[s] = append(preds[s], i)
+		}
+	}
+	idom := dominators(succs, 0)
+	dominates := func(a, b int) bool {
+		for idom[b] >= 0 && b != a {
+			if idom[b] == b {
+				return false
+			}
+			b = idom[b]
+		}
+		return b == a
+	}
+
+	loops := make(map[uint64][]uint64)
+	for i, b := range blocks {
+		if op := OpCode(code[b.last]); op != JUMP && op != JUMPI {
+			continue
+		}
+		for _, h := range succs[i] {
+			if h == exit || idom[i] < 0 || !dominates(h, i) {
+				continue
+			}
+			// the body reaches the back edge without passing the header
+			body := map[int]bool{h: true, i: true}
+			work := []int{i}
+			for len(work) > 0 {
+				v := work[len(work)-1]
+				work = work[:len(work)-1]
+				if v == h {
+					continue
+				}
+				for _, p := range preds[v] {
+					if !body[p] {
+						body[p] = true
+						work = append(work, p)
+					}
+				}
+			}
+			for j := range body {
+				if OpCode(code[blocks[j].last]) == JUMPI {
+					loops[b.last] = append(loops[b.last], blocks[j].last)
+				}
+			}
+		}
+		sort.Slice(loops[b.last], func(x, y int) bool { return loops[b.last][x] < loops[b.last][y] })
+	}
+	return loops
+}
+
+// join returns the pc where the branches of the JUMPI at pc join, or -1.
+func (c *controlFlow) join(pc uint64) int64 {
+	if end, ok := c.joins[pc]; ok {
+		return end
+	}
+	return -1
+}
+
+// loop reports whether the JUMPI at cond is part of the loop closed by the
+// jump at pc.
+func (c *controlFlow) loop(pc, cond uint64) bool {
+	for _, p := range c.loops[pc] {
+		if p == cond {
+			return true
+		}
+	}
+	return false
+}
+
+// controlFlow returns the analysis of the contract code, cached by code
+// hash.
+func (in *Interpreter) controlFlow(contract *Contract) *controlFlow {
+	if contract.CodeHash == (common.Hash{}) {
+		return newControlFlow(contract.Code)
+	}
+	if c, ok := in.controlFlows[contract.CodeHash]; ok {
+		return c
+	}
+	c := newControlFlow(contract.Code)
+	in.controlFlows[contract.CodeHash] = c
+	return c
+}
+
+// controlRegion is the part of an execution that depends on a tainted
+// branch.
+type controlRegion struct {
+	end  int64 // pc of the join, -1 for the end of the frame
+	flag int   // taint of the condition
+}
+
+// implicitFlow tracks the control regions of a frame.
+type implicitFlow struct {
+	cf      *controlFlow
+	regions []controlRegion
+	flag    int // taint of the open regions
+}
+
+func newImplicitFlow(cf *controlFlow) *implicitFlow {
+	return &implicitFlow{cf: cf}
+}
+
+// reach is called before the instruction at pc is executed, it closes the
+// regions joining at pc.
+func (f *implicitFlow) reach(pc uint64) {
+	if len(f.regions) == 0 {
+		return
+	}
+	regions := f.regions[:0]
+	f.flag = SAFE_FLAG
+	for _, r := range f.regions {
+		if r.end != int64(pc) {
+			regions = append(regions, r)
+			f.flag |= r.flag
+		}
+	}
+	f.regions = regions
+}
+
+// branch is called for the JUMPI at pc on a condition with the given taint.
+func (f *implicitFlow) branch(pc uint64, cond int) {
+	flag := cond & (SOURCE_FLAGS | CALLDATA_WORD_FLAGS)
+	if flag == SAFE_FLAG {
+		return
+	}
+	flag |= IMPLICIT_FLAG
+	end := f.cf.join(pc)
+	// a loop branches to the same join on every iteration
+	for i := range f.regions {
+		if f.regions[i].end == end {
+			f.regions[i].flag |= flag
+			f.flag |= flag
+			return
+		}
+	}
+	f.regions = append(f.regions, controlRegion{end: end, flag: flag})
+	f.flag |= flag
+}
+
+// apply adds the taint of the open regions to the value pushed by op.
+func (f *implicitFlow) apply(op OpCode, taint_stack *TaintStack) {
+	if f.flag == SAFE_FLAG || !pushesValue(op) || taint_stack.len() == 0 {
+		return
+	}
+	taint_stack.data[taint_stack.len()-1] |= f.flag
+}
+
+// pushesValue reports whether op pushes a new value onto the stack.
+func pushesValue(op OpCode) bool {
+	switch {
+	case op >= SWAP1 && op <= SWAP16, op >= LOG0 && op <= LOG4:
+		return false
+	}
+	switch op {
+	case STOP, POP, MSTORE, MSTORE8, SSTORE, JUMP, JUMPI, JUMPDEST,
+		CALLDATACOPY, CODECOPY, EXTCODECOPY, RETURNDATACOPY,
+		RETURN, REVERT, SELFDESTRUCT:
+		return false
+	}
+	return true
+}
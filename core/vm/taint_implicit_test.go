@@ -0,0 +1,52 @@
+// Author: Jianbo-Gao
+// Tests for the control flow analysis of implicit flows.
+
+package vm
+
+import (
+	"testing"
+)
+
+func TestControlFlowJoins(t *testing.T) {
+	code := []byte{
+		// 0: if (c) { ... } joins at 8
+		byte(PUSH1), 1, byte(PUSH1), 8, byte(JUMPI),
+		byte(PUSH1), 0, byte(POP),
+		// 8: require(c) never joins the revert
+		byte(JUMPDEST), byte(PUSH1), 1, byte(PUSH1), 19, byte(JUMPI),
+		byte(PUSH1), 0, byte(DUP1), byte(REVERT), 0xfe,
+		// 19: if (c) return; has no join before the exit
+		byte(JUMPDEST), byte(PUSH1), 1, byte(PUSH1), 26, byte(JUMPI),
+		byte(STOP),
+		byte(JUMPDEST), byte(STOP),
+	}
+	c := newControlFlow(code)
+	for pc, want := range map[uint64]int64{4: 8, 13: 19, 24: -1} {
+		if end := c.join(pc); end != want {
+			t.Errorf("JUMPI at %d: expected join at %d, got %d", pc, want, end)
+		}
+	}
+}
+
+func TestControlFlowLoops(t *testing.T) {
+	// for (i = 0; i < n; i++) {} jumps back at 17
+	loop := []byte{
+		byte(PUSH1), 0, byte(JUMPDEST), byte(DUP1), byte(PUSH1), 0, byte(CALLDATALOAD),
+		byte(GT), byte(ISZERO), byte(PUSH1), 18, byte(JUMPI),
+		byte(PUSH1), 1, byte(ADD), byte(PUSH1), 2, byte(JUMP),
+		byte(JUMPDEST), byte(STOP),
+	}
+	if c := newControlFlow(loop); !c.loop(17, 11) || len(c.loops) != 1 {
+		t.Errorf("loop: expected the JUMPI at 11 in the loop closed at 17, got %v", c.loops)
+	}
+	// an internal function returning from 17 to 9 is no loop
+	call := []byte{
+		byte(PUSH1), 9, byte(PUSH1), 0, byte(CALLDATALOAD), byte(PUSH1), 11, byte(JUMP), 0xfe,
+		byte(JUMPDEST), byte(STOP),
+		byte(JUMPDEST), byte(PUSH1), 16, byte(JUMPI), 0xfe,
+		byte(JUMPDEST), byte(JUMP),
+	}
+	if c := newControlFlow(call); len(c.loops) != 0 {
+		t.Errorf("call: expected no loops, got %v", c.loops)
+	}
+}
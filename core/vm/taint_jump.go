@@ -0,0 +1,39 @@
+// Author: Jianbo-Gao
+// Detecting jumps to destinations chosen by an attacker.
+
+package vm
+
+// A JUMP or JUMPI whose destination is derived from a source, e.g. a
+// function pointer loaded from storage that was written with calldata, lets
+// an attacker redirect control flow to any JUMPDEST of the code. The
+// concrete destination and its sources are reported with high severity.
+//
+// With Config.TaintImplicitFlow, every value pushed under a tainted branch
+// carries the sources of the condition, including the constant return
+// addresses of internal calls. Destinations carrying IMPLICIT_FLAG are not
+// reported.
+
+// taintedJumpDetector records jumps to tainted destinations.
+type taintedJumpDetector struct{}
+
+// NewTaintedJumpDetector returns the tainted jump destination detector.
+func NewTaintedJumpDetector() Detector {
+	return taintedJumpDetector{}
+}
+
+func (taintedJumpDetector) CaptureOp(step *DetectorStep) DetectorResult {
+	if step.Op != JUMP && step.Op != JUMPI {
+		return nil
+	}
+	td := step.TaintStack.Back(0)
+	if td&SOURCE_FLAGS == 0 || td&IMPLICIT_FLAG > 0 || TAINTED_JUMP_FLAG == SAFE_FLAG {
+		return nil
+	}
+	r := step.Report()
+	if r.recorded(step.Contract, step.Pc, TAINTED_JUMP_FLAG) {
+		return nil
+	}
+	step.Record(TAINTED_JUMP_FLAG, []int{td}, nil, step.Operand(0))
+	r.Findings[len(r.Findings)-1].severity = "high"
+	return nil
+}
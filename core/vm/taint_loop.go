@@ -0,0 +1,97 @@
+// Author: Jianbo-Gao
+// Detecting loops bounded by attacker controlled values.
+
+package vm
+
+import (
+	"math/big"
+)
+
+// A loop whose exit condition depends on calldata or tainted storage, like
+//
+//	for (uint i = 0; i < receivers.length; i++) ...
+//
+// in batchTransfer, runs as often as the attacker wants and can exhaust the
+// block gas limit. A backward JUMP or taken JUMPI closing a natural loop of
+// the code, see taint_implicit.go, is an iteration of every JUMPI of the
+// loop. A JUMPI deciding on a condition carrying CALLDATA_FLAG or
+// STORAGE_FLAG is reported once, with the number of times its loop jumped
+// back and the gas used since the condition was first decided. As for
+// jumps, conditions carrying IMPLICIT_FLAG are not reported.
+
+// loopBoundDetector records loops with tainted exit conditions.
+type loopBoundDetector struct{}
+
+// NewLoopBoundDetector returns the tainted loop bound detector.
+func NewLoopBoundDetector() Detector {
+	return loopBoundDetector{}
+}
+
+// loopCond is a JUMPI deciding on a tainted condition.
+type loopCond struct {
+	pc       uint64
+	taints   []int
+	operands []*big.Int // destination and condition of the last execution
+	gas      uint64     // gas left at the first execution
+	back     uint64     // times a loop around the JUMPI jumped back
+	finding  *Finding
+}
+
+func (loopBoundDetector) CaptureOp(step *DetectorStep) DetectorResult {
+	if step.Op != JUMP && step.Op != JUMPI {
+		return nil
+	}
+	r := step.Report()
+	dest := step.Stack.Back(0)
+	if step.Op == JUMPI {
+		if tc := step.TaintStack.Back(1); tc&(CALLDATA_FLAG|STORAGE_FLAG) > 0 && tc&IMPLICIT_FLAG == 0 && LOOP_BOUND_FLAG != SAFE_FLAG {
+			r.loopCond(step, tc)
+		}
+		if step.Stack.Back(1).Sign() == 0 {
+			return nil
+		}
+	}
+	conds := r.loopConds[step.Contract]
+	if len(conds) == 0 || !dest.IsUint64() || dest.Uint64() >= step.Pc {
+		return nil
+	}
+	cf := step.EVM.interpreter.controlFlow(step.Contract)
+	for _, c := range conds {
+		if cf.loop(step.Pc, c.pc) {
+			c.back++
+			r.loopIteration(step, c)
+		}
+	}
+	return nil
+}
+
+// loopCond remembers the execution of a JUMPI on a condition with the given
+// taint.
+func (r *TaintReport) loopCond(step *DetectorStep, tc int) {
+	if r.loopConds == nil {
+		r.loopConds = make(map[*Contract][]*loopCond)
+	}
+	var c *loopCond
+	for _, cond := range r.loopConds[step.Contract] {
+		if cond.pc == step.Pc {
+			c = cond
+		}
+	}
+	if c == nil {
+		c = &loopCond{pc: step.Pc, gas: step.Contract.Gas}
+		r.loopConds[step.Contract] = append(r.loopConds[step.Contract], c)
+	}
+	c.taints = []int{step.TaintStack.Back(0), tc}
+	c.operands = []*big.Int{step.Operand(0), step.Operand(1)}
+}
+
+// loopIteration records or updates the finding of the loop condition c
+// after the loop jumped back.
+func (r *TaintReport) loopIteration(step *DetectorStep, c *loopCond) {
+	if c.finding == nil {
+		r.record(step.EVM, step.Contract, c.pc, JUMPI, LOOP_BOUND_FLAG, c.taints, nil, c.operands...)
+		c.finding = r.Findings[len(r.Findings)-1]
+	}
+	c.finding.Iterations = c.back
+	c.finding.GasUsed = c.gas - step.Contract.Gas
+}
@@ -4,11 +4,24 @@
 package vm
 
 import "fmt"
+import "sort"
 import "encoding/json"
 
-// Memory implements a simple memory model for the ethereum virtual machine.
+// taintSpan is a range [start, end) of memory bytes with the same taint.
+type taintSpan struct {
+	start, end uint64
+	taint      int
+}
+
+// TaintMemory records the taint of every byte of the EVM memory. Memory is
+// mostly untainted or holds whole words of a single taint, so instead of a
+// taint per byte it keeps the sorted, disjoint spans of tainted bytes, with
+// adjacent spans of the same taint merged. Bytes outside of any span are
+// untainted.
 type TaintMemory struct {
-	store       []int
+	spans       []taintSpan
+	size        uint64
+	buf         []taintSpan // spans replacing a range, reused by every write
 	lastGasCost uint64
 }
 
@@ -20,21 +33,85 @@ func NewTaintMemory() *TaintMemory {
 func (m *TaintMemory) Set(offset, size uint64, value []int) {
 	// length of store may never be less than offset + size.
 	// The store should be resized PRIOR to setting the memory
-	if size > uint64(len(m.store)) {
+	if size > m.size {
 		panic("INVALID memory: store empty")
 	}
+	// like copy, only the bytes held by value are set
+	if size > uint64(len(value)) {
+		size = uint64(len(value))
+	}
 
 	// It's possible the offset is greater than 0 and size equals 0. This is because
 	// the calcMemSize (common.go) could potentially return 0 when size is zero (NO-OP)
 	if size > 0 {
-		copy(m.store[offset:offset+size], value)
+		lo, hi, buf := m.cut(offset, offset+size)
+		for i, t := range value[:size] {
+			if t != SAFE_FLAG {
+				buf = appendSpan(buf, taintSpan{offset + uint64(i), offset + uint64(i) + 1, t})
+			}
+		}
+		m.splice(lo, hi, offset+size, buf)
+	}
+}
+
+// Fill sets offset + size to the taint
+func (m *TaintMemory) Fill(offset, size uint64, taint int) {
+	if size > m.size {
+		panic("INVALID memory: store empty")
+	}
+	if size > 0 {
+		lo, hi, buf := m.cut(offset, offset+size)
+		if taint != SAFE_FLAG {
+			buf = appendSpan(buf, taintSpan{offset, offset + size, taint})
+		}
+		m.splice(lo, hi, offset+size, buf)
+	}
+}
+
+// cut returns the spans m.spans[lo:hi] touching the range [start, end), and
+// the buffer of their replacement holding the bytes before start.
+func (m *TaintMemory) cut(start, end uint64) (lo, hi int, buf []taintSpan) {
+	lo = sort.Search(len(m.spans), func(i int) bool { return m.spans[i].end >= start })
+	hi = sort.Search(len(m.spans), func(i int) bool { return m.spans[i].start > end })
+	buf = m.buf[:0]
+	if lo < hi && m.spans[lo].start < start {
+		buf = append(buf, taintSpan{m.spans[lo].start, start, m.spans[lo].taint})
+	}
+	return lo, hi, buf
+}
+
+// splice replaces the spans m.spans[lo:hi] with buf and the bytes after end.
+func (m *TaintMemory) splice(lo, hi int, end uint64, buf []taintSpan) {
+	if lo < hi && m.spans[hi-1].end > end {
+		buf = appendSpan(buf, taintSpan{end, m.spans[hi-1].end, m.spans[hi-1].taint})
 	}
+	var (
+		n    = len(m.spans)
+		size = n - (hi - lo) + len(buf)
+	)
+	if size > n {
+		m.spans = append(m.spans, buf[:size-n]...)
+	}
+	copy(m.spans[lo+len(buf):], m.spans[hi:n])
+	copy(m.spans[lo:], buf)
+	m.spans = m.spans[:size]
+	m.buf = buf
+}
+
+// appendSpan appends s to spans, merging it into the last span if they are
+// adjacent with the same taint.
+func appendSpan(spans []taintSpan, s taintSpan) []taintSpan {
+	if n := len(spans); n > 0 && spans[n-1].end == s.start && spans[n-1].taint == s.taint {
+		spans[n-1].end = s.end
+		return spans
+	}
+	return append(spans, s)
 }
 
 // Resize resizes the memory to size
 func (m *TaintMemory) Resize(size uint64) {
-	if uint64(m.Len()) < size {
-		m.store = append(m.store, make([]int, size-uint64(m.Len()))...)
+	if m.size < size {
+		m.size = size
 	}
 }
 
@@ -44,45 +121,90 @@ func (m *TaintMemory) Get(offset, size int64) (cpy []int) {
 		return nil
 	}
 
-	if len(m.store) > int(offset) {
+	if m.size > uint64(offset) {
 		cpy = make([]int, size)
-		copy(cpy, m.store[offset:offset+size])
-
+		start, end := uint64(offset), uint64(offset+size)
+		for _, s := range m.spans[m.search(start):] {
+			if s.start >= end {
+				break
+			}
+			from, to := s.start, s.end
+			if from < start {
+				from = start
+			}
+			if to > end {
+				to = end
+			}
+			for i := from; i < to; i++ {
+				cpy[i-start] = s.taint
+			}
+		}
 		return
 	}
 
 	return
 }
 
-// GetPtr returns the offset + size
-func (m *TaintMemory) GetPtr(offset, size int64) []int {
+// Union returns the union of the taint of offset + size
+func (m *TaintMemory) Union(offset, size uint64) int {
+	taint := SAFE_FLAG
 	if size == 0 {
-		return nil
+		return taint
 	}
-
-	if len(m.store) > int(offset) {
-		return m.store[offset : offset+size]
+	for _, s := range m.spans[m.search(offset):] {
+		if s.start >= offset+size {
+			break
+		}
+		taint |= s.taint
 	}
+	return taint
+}
 
-	return nil
+// search returns the index of the first span ending after offset.
+func (m *TaintMemory) search(offset uint64) int {
+	return sort.Search(len(m.spans), func(i int) bool { return m.spans[i].end > offset })
 }
 
-// Len returns the length of the backing slice
+// Len returns the length of the memory
 func (m *TaintMemory) Len() int {
-	return len(m.store)
+	return int(m.size)
+}
+
+// labelTaint returns a copy of taint with the label added to every byte.
+func labelTaint(taint []int, label int) []int {
+	if taint == nil {
+		return nil
+	}
+	t_value := make([]int, len(taint))
+	for i, t := range taint {
+		t_value[i] = t | label
+	}
+	return t_value
 }
 
-// Data returns the backing slice
-func (m *TaintMemory) Data() []int {
-	return m.store
+// getTaint returns the taint of a slice of the data based on the start and
+// size, the zero padding beyond the data is untainted, like getData.
+func getTaint(data []int, start uint64, size uint64) []int {
+	length := uint64(len(data))
+	if start > length {
+		start = length
+	}
+	end := start + size
+	if end > length {
+		end = length
+	}
+	t_value := make([]int, size)
+	copy(t_value, data[start:end])
+	return t_value
 }
 
 func (m *TaintMemory) Print() {
-	fmt.Printf("### mem %d bytes ###\n", len(m.store))
-	if len(m.store) > 0 {
+	store := m.Get(0, int64(m.size))
+	fmt.Printf("### mem %d bytes ###\n", len(store))
+	if len(store) > 0 {
 		addr := 0
-		for i := 0; i+32 <= len(m.store); i += 32 {
-			fmt.Printf("%03d: % x\n", addr, m.store[i:i+32])
+		for i := 0; i+32 <= len(store); i += 32 {
+			fmt.Printf("%03d: % x\n", addr, store[i:i+32])
 			addr++
 		}
 	} else {
@@ -92,7 +214,7 @@ func (m *TaintMemory) Print() {
 }
 
 func (m *TaintMemory) JPrint() {
-	if j_data, err := json.Marshal(m.store); err == nil {
+	if j_data, err := json.Marshal(m.Get(0, int64(m.size))); err == nil {
 		fmt.Printf("TaintMemory:%s\n", j_data)
 	}
 }
@@ -0,0 +1,96 @@
+// Author: Jianbo-Gao
+// Tests for the taint memory.
+
+package vm
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestTaintMemory(t *testing.T) {
+	tests := []struct {
+		set   func(m *TaintMemory)
+		want  []int
+		spans int
+	}{
+		// untainted memory keeps no span
+		{func(m *TaintMemory) { m.Set(0, 4, []int{0, 0, 0, 0}) }, []int{0, 0, 0, 0, 0, 0, 0, 0}, 0},
+		{func(m *TaintMemory) { m.Fill(2, 4, CALLDATA_FLAG) }, []int{0, 0, 1, 1, 1, 1, 0, 0}, 1},
+		// bytes of the same taint are merged
+		{func(m *TaintMemory) { m.Set(0, 4, []int{1, 1, 2, 2}); m.Fill(4, 2, 2) }, []int{1, 1, 2, 2, 2, 2, 0, 0}, 2},
+		// overwriting the middle of a span splits it
+		{func(m *TaintMemory) { m.Fill(0, 8, 1); m.Fill(3, 2, 0) }, []int{1, 1, 1, 0, 0, 1, 1, 1}, 2},
+		{func(m *TaintMemory) { m.Fill(0, 8, 1); m.Set(3, 2, []int{2, 2}) }, []int{1, 1, 1, 2, 2, 1, 1, 1}, 3},
+		{func(m *TaintMemory) { m.Fill(0, 2, 1); m.Fill(6, 2, 1); m.Fill(2, 4, 1) }, []int{1, 1, 1, 1, 1, 1, 1, 1}, 1},
+		{func(m *TaintMemory) { m.Fill(0, 2, 1); m.Fill(4, 2, 2); m.Fill(1, 4, 4) }, []int{1, 4, 4, 4, 4, 2, 0, 0}, 3},
+		// like copy, a short value only sets the bytes it holds
+		{func(m *TaintMemory) { m.Fill(0, 8, 1); m.Set(2, 4, []int{2}) }, []int{1, 1, 2, 1, 1, 1, 1, 1}, 3},
+	}
+	for i, test := range tests {
+		m := NewTaintMemory()
+		m.Resize(8)
+		test.set(m)
+		if got := m.Get(0, 8); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("test %d: got %v, want %v", i, got, test.want)
+		}
+		if len(m.spans) != test.spans {
+			t.Errorf("test %d: got %d spans, want %d", i, len(m.spans), test.spans)
+		}
+	}
+}
+
+// TestTaintMemoryRandom checks the taint memory against a taint per byte.
+func TestTaintMemoryRandom(t *testing.T) {
+	const size = 256
+	var (
+		rnd   = rand.New(rand.NewSource(1))
+		m     = NewTaintMemory()
+		store = make([]int, size)
+	)
+	m.Resize(size)
+	for n := 0; n < 5000; n++ {
+		offset := uint64(rnd.Intn(size))
+		length := uint64(rnd.Intn(size - int(offset) + 1))
+		if rnd.Intn(2) == 0 {
+			taint := rnd.Intn(3)
+			m.Fill(offset, length, taint)
+			for i := offset; i < offset+length; i++ {
+				store[i] = taint
+			}
+		} else {
+			value := make([]int, rnd.Intn(int(length)+1))
+			for i := range value {
+				value[i] = rnd.Intn(3)
+			}
+			m.Set(offset, length, value)
+			copy(store[offset:offset+length], value)
+		}
+		if got := m.Get(0, size); !reflect.DeepEqual(got, store) {
+			t.Fatalf("write %d: got %v, want %v", n, got, store)
+		}
+		start, end := rnd.Intn(size), rnd.Intn(size+1)
+		if start > end {
+			start, end = end, start
+		}
+		want := SAFE_FLAG
+		for _, taint := range store[start:end] {
+			want |= taint
+		}
+		if got := m.Union(uint64(start), uint64(end-start)); got != want {
+			t.Fatalf("write %d: union of %d-%d = %d, want %d", n, start, end, got, want)
+		}
+		for i, s := range m.spans {
+			if s.taint == SAFE_FLAG || s.start >= s.end {
+				t.Fatalf("write %d: invalid span %v", n, s)
+			}
+			if i > 0 {
+				prev := m.spans[i-1]
+				if prev.end > s.start || prev.end == s.start && prev.taint == s.taint {
+					t.Fatalf("write %d: spans %v and %v not ordered and merged", n, prev, s)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,46 @@
+// Author: Jianbo-Gao
+// Detecting overflows of types narrower than 256 bits.
+
+package vm
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Arithmetic on uint8 ... uint248 and int8 ... int248 is done on full
+// words, the compiler cuts the result down to the type with an AND mask or
+// a SIGNEXTEND. The result of tainted arithmetic is marked with
+// ARITHMETIC_FLAG until it is narrowed, narrowing it overflows the type if
+// significant bits are dropped.
+//
+// Values loaded from storage or hashed are not fresh results, packed
+// storage slots are masked to their fields without any arithmetic, so the
+// mark is cleared there.
+
+// narrowMaskWidth returns the width of a uint8 ... uint248 mask, or 0 if the
+// mask does not narrow to such a type.
+func narrowMaskWidth(mask *big.Int) uint {
+	width := uint(mask.BitLen())
+	if width == 0 || width%8 != 0 || width >= 256 {
+		return 0
+	}
+	// 2^width - 1 has all its bits set
+	if new(big.Int).Add(mask, common.Big1).BitLen() != int(width)+1 {
+		return 0
+	}
+	return width
+}
+
+// narrowArithmetic records a tainted arithmetic result narrowed by the
+// operation of the step, and returns the taint flags of the narrowed value.
+// Changed is whether the narrowing dropped significant bits.
+func narrowArithmetic(step *DetectorStep, changed bool, taints []int, result *big.Int, operands ...*big.Int) int {
+	temp_flag := POTENTIAL_OVERFLOW_FLAG
+	if changed {
+		temp_flag |= OVERFLOW_FLAG
+	}
+	temp_flag |= step.Record(temp_flag, taints, result, operands...)
+	return temp_flag
+}
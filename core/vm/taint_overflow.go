@@ -0,0 +1,206 @@
+// Author: Jianbo-Gao
+// Detecting integer overflows of tainted arithmetic.
+
+package vm
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/math"
+)
+
+// overflowDetector checks arithmetic on tainted operands for overflows,
+// underflows, signed overflows and overflows of narrow types. Whether an
+// overflow is protected is decided once its result reaches a guard, see
+// taint_guard.go, and it is ranked by the sinks the result reaches, see
+// taint_sink.go.
+type overflowDetector struct{}
+
+// NewOverflowDetector returns the integer overflow detector.
+func NewOverflowDetector() Detector {
+	return overflowDetector{}
+}
+
+func (overflowDetector) CaptureOp(step *DetectorStep) DetectorResult {
+	var (
+		r           = step.Report()
+		taint_stack = step.TaintStack
+	)
+	if step.EVM.interpreter.cfg.JumpTable[step.Op].writes || (step.Op == CALL && step.Stack.Back(2).Sign() > 0) {
+		r.effect()
+	}
+
+	switch op := step.Op; {
+	case op == ADD || op == SUB || op == MUL || op == EXP || op == SHL:
+		tx, ty := taint_stack.Back(0), taint_stack.Back(1)
+		if (tx|ty)&SOURCE_FLAGS == 0 {
+			return nil
+		}
+		x, y := step.Operand(0), step.Operand(1)
+		return func(step *DetectorStep) {
+			temp_flag := checkArithmetic(step, x, y, tx, ty) | ARITHMETIC_FLAG
+			step.TaintStack.Set(0, step.TaintStack.Back(0)|temp_flag)
+		}
+
+	case op == ADDMOD || op == MULMOD:
+		tx, ty, tz := taint_stack.Back(0), taint_stack.Back(1), taint_stack.Back(2)
+		if (tx|ty)&SOURCE_FLAGS == 0 || step.Stack.Back(2).Sign() == 0 {
+			return nil
+		}
+		x, y, z := step.Operand(0), step.Operand(1), step.Operand(2)
+		// the intermediate result is computed exactly, it cannot overflow
+		return func(step *DetectorStep) {
+			temp_flag := POTENTIAL_OVERFLOW_FLAG
+			temp_flag |= step.Record(temp_flag, []int{tx, ty, tz}, step.Operand(0), x, y, z)
+			step.TaintStack.Set(0, step.TaintStack.Back(0)|temp_flag)
+		}
+
+	case op == SDIV || op == SMOD:
+		tx, ty := taint_stack.Back(0), taint_stack.Back(1)
+		if (tx|ty)&SOURCE_FLAGS == 0 {
+			return nil
+		}
+		r.signedUse(tx | ty)
+		x, y := step.Operand(0), step.Operand(1)
+		return func(step *DetectorStep) {
+			temp_flag := SIGNED_FLAG
+			if checkSignedDivOverflow(x, y) {
+				temp_flag |= r.recordSigned(step.EVM, step.Contract, step.Pc, step.Op, true, []int{tx, ty}, step.Operand(0), x, y)
+			}
+			step.TaintStack.Set(0, step.TaintStack.Back(0)|temp_flag)
+		}
+
+	case op == SIGNEXTEND:
+		tx, ty := taint_stack.Back(0), taint_stack.Back(1)
+		if ty&SOURCE_FLAGS == 0 || step.Stack.Back(0).Cmp(big31) >= 0 {
+			return nil
+		}
+		r.signedUse(ty)
+		back, num := step.Operand(0), step.Operand(1)
+		return func(step *DetectorStep) {
+			temp_flag := SIGNED_FLAG
+			res := step.Operand(0)
+			switch {
+			case ty&ARITHMETIC_FLAG == 0:
+				// a tainted input out of range is not an overflow
+			case checkSignExtendOverflow(num, res):
+				temp_flag |= r.recordSigned(step.EVM, step.Contract, step.Pc, step.Op, true, []int{tx, ty}, res, back, num)
+			default:
+				// an arithmetic result that fits the narrow type, see
+				// taint_narrow.go
+				temp_flag |= narrowArithmetic(step, false, []int{tx, ty}, res, back, num)
+			}
+			step.TaintStack.Set(0, tx|ty&^ARITHMETIC_FLAG|temp_flag)
+		}
+
+	case op == AND:
+		// masking an arithmetic result to a narrow type, see taint_narrow.go
+		tx, ty := taint_stack.Back(0), taint_stack.Back(1)
+		x, y := step.Stack.Back(0), step.Stack.Back(1)
+		var value *big.Int
+		var width uint
+		if w := narrowMaskWidth(y); w > 0 && tx&ARITHMETIC_FLAG > 0 && ty == SAFE_FLAG {
+			value, width = x, w
+		} else if w := narrowMaskWidth(x); w > 0 && ty&ARITHMETIC_FLAG > 0 && tx == SAFE_FLAG {
+			value, width = y, w
+		} else {
+			return nil
+		}
+		changed := value.BitLen() > int(width)
+		x, y = step.Operand(0), step.Operand(1)
+		return func(step *DetectorStep) {
+			temp_flag := narrowArithmetic(step, changed, []int{tx, ty}, step.Operand(0), x, y)
+			step.TaintStack.Set(0, step.TaintStack.Back(0)&^ARITHMETIC_FLAG|temp_flag)
+		}
+
+	case op == SLT || op == SGT:
+		r.signedUse(taint_stack.Back(0) | taint_stack.Back(1))
+
+	case op == SAR:
+		if ty := taint_stack.Back(1); ty&SOURCE_FLAGS > 0 {
+			r.signedUse(ty)
+			return markSigned
+		}
+
+	case op == SLOAD || op == SHA3:
+		// stored and hashed values are no fresh arithmetic results
+		return clearArithmetic
+
+	case op == JUMPI:
+		r.guard(step.EVM.depth, taint_stack.Back(1))
+
+	case op == SSTORE:
+		r.sink(sinkStore, taint_stack.Back(1))
+
+	case op == CALL || op == CALLCODE:
+		r.sink(sinkCall, taint_stack.Back(1)|taint_stack.Back(2))
+
+	case op >= LOG0 && op <= LOG4:
+		t_log := SAFE_FLAG
+		for i := 0; i < int(op-LOG0); i++ {
+			t_log |= taint_stack.Back(2 + i)
+		}
+		t_log |= step.TaintMemory.Union(step.Stack.Back(0).Uint64(), step.Stack.Back(1).Uint64())
+		r.sink(sinkLog, t_log)
+
+	case op == RETURN && step.EVM.depth == 1:
+		t_ret := step.TaintMemory.Union(step.Stack.Back(0).Uint64(), step.Stack.Back(1).Uint64())
+		r.sink(sinkReturn, t_ret)
+	}
+	return nil
+}
+
+// checkArithmetic checks the result of ADD, SUB, MUL, EXP or SHL on the
+// operands x and y, and returns the taint flags of the result.
+func checkArithmetic(step *DetectorStep, x, y *big.Int, tx, ty int) int {
+	var (
+		temp_flag = SAFE_FLAG
+		res       = step.Operand(0)
+		taints    = []int{tx, ty}
+		signed    bool
+	)
+	switch step.Op {
+	case ADD:
+		if res.Cmp(x) < 0 || res.Cmp(y) < 0 {
+			temp_flag |= OVERFLOW_FLAG
+		}
+		temp_flag |= POTENTIAL_OVERFLOW_FLAG
+		signed = checkSignedAddOverflow(x, y)
+	case SUB:
+		if y.Cmp(x) > 0 {
+			temp_flag |= UNDERFLOW_FLAG
+		}
+		temp_flag |= POTENTIAL_UNDERFLOW_FLAG
+		signed = checkSignedSubOverflow(x, y)
+	case MUL:
+		if x.Sign() != 0 && math.U256(new(big.Int).Div(res, x)).Cmp(y) != 0 {
+			temp_flag |= OVERFLOW_FLAG
+		}
+		temp_flag |= POTENTIAL_OVERFLOW_FLAG
+		signed = checkSignedMulOverflow(x, y)
+	case EXP:
+		if checkExpOverflow(x, y) {
+			temp_flag |= OVERFLOW_FLAG
+		}
+		temp_flag |= POTENTIAL_OVERFLOW_FLAG
+	case SHL:
+		// shifting left by n multiplies by 2^n, it is checked like MUL
+		if checkShlOverflow(x, y) {
+			temp_flag |= OVERFLOW_FLAG
+		}
+		temp_flag |= POTENTIAL_OVERFLOW_FLAG
+	}
+	temp_flag |= step.Record(temp_flag, taints, res, x, y)
+	if signed {
+		temp_flag |= step.Report().recordSigned(step.EVM, step.Contract, step.Pc, step.Op, false, taints, res, x, y)
+	}
+	return temp_flag
+}
+
+func markSigned(step *DetectorStep) {
+	step.TaintStack.Set(0, step.TaintStack.Back(0)|SIGNED_FLAG)
+}
+
+func clearArithmetic(step *DetectorStep) {
+	step.TaintStack.Set(0, step.TaintStack.Back(0)&^ARITHMETIC_FLAG)
+}
@@ -0,0 +1,118 @@
+// Author: Jianbo-Gao
+// Detecting reentrancy through calls to untrusted addresses.
+
+package vm
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// A CALL is open to reentrancy when it forwards more gas than the stipend to
+// an address derived from a source, e.g.
+//
+//	uint amount = balances[msg.sender];
+//	msg.sender.call.value(amount)();
+//	balances[msg.sender] = 0;
+//
+// The callee may call back before the frame writes its state, so a slot
+// read before the call and written after it, by the same frame, is stale
+// during the reentrant call. Addresses that are constants, or loaded from
+// storage never written with tainted data, are trusted. transfer and send
+// forward at most the stipend, which is too little to call back.
+
+// reentrancyDetector records calls to untrusted addresses followed by
+// writes to slots read before the call.
+type reentrancyDetector struct{}
+
+// NewReentrancyDetector returns the reentrancy detector.
+func NewReentrancyDetector() Detector {
+	return reentrancyDetector{}
+}
+
+// reentrancyFrame holds the storage accesses of a frame.
+type reentrancyFrame struct {
+	reads map[common.Hash]bool // slots read by the frame
+	calls []*reentrantCall     // calls to untrusted addresses, in order
+}
+
+// reentrantCall is a call to an untrusted address.
+type reentrantCall struct {
+	pc       uint64
+	taints   []int
+	operands []*big.Int
+	reads    map[common.Hash]bool // slots read before the call
+	finding  *Finding             // recorded on the first stale write
+}
+
+func (reentrancyDetector) CaptureOp(step *DetectorStep) DetectorResult {
+	switch step.Op {
+	case SLOAD:
+		frame := step.Report().reentrancyFrame(step.Contract)
+		frame.reads[common.BigToHash(step.Stack.Back(0))] = true
+
+	case CALL:
+		tg, ta, tv := step.TaintStack.Back(0), step.TaintStack.Back(1), step.TaintStack.Back(2)
+		if ta&SOURCE_FLAGS == 0 || step.Stack.Back(0).Cmp(new(big.Int).SetUint64(params.CallStipend)) <= 0 {
+			return nil
+		}
+		frame := step.Report().reentrancyFrame(step.Contract)
+		reads := make(map[common.Hash]bool, len(frame.reads))
+		for slot := range frame.reads {
+			reads[slot] = true
+		}
+		frame.calls = append(frame.calls, &reentrantCall{
+			pc:       step.Pc,
+			taints:   []int{tg, ta, tv},
+			operands: []*big.Int{step.Operand(0), step.Operand(1), step.Operand(2)},
+			reads:    reads,
+		})
+
+	case SSTORE:
+		frame := step.Report().reentrancyFrames[step.Contract]
+		if frame == nil {
+			return nil
+		}
+		slot := common.BigToHash(step.Stack.Back(0))
+		for _, call := range frame.calls {
+			if call.reads[slot] {
+				step.Report().reentrant(step, call, slot)
+			}
+		}
+	}
+	return nil
+}
+
+// reentrancyFrame returns the storage accesses of the frame running
+// contract.
+func (r *TaintReport) reentrancyFrame(contract *Contract) *reentrancyFrame {
+	if r.reentrancyFrames == nil {
+		r.reentrancyFrames = make(map[*Contract]*reentrancyFrame)
+	}
+	frame, ok := r.reentrancyFrames[contract]
+	if !ok {
+		frame = &reentrancyFrame{reads: make(map[common.Hash]bool)}
+		r.reentrancyFrames[contract] = frame
+	}
+	return frame
+}
+
+// reentrant is called for a write to slot after call, the slot is added to
+// the finding of the call.
+func (r *TaintReport) reentrant(step *DetectorStep, call *reentrantCall, slot common.Hash) {
+	if call.finding == nil {
+		r.record(step.EVM, step.Contract, call.pc, CALL, REENTRANCY_FLAG, call.taints, nil, call.operands...)
+		call.finding = r.Findings[len(r.Findings)-1]
+		if input := step.Contract.Input; len(input) >= 4 {
+			call.finding.Selector = common.CopyBytes(input[:4])
+		}
+	}
+	for _, s := range call.finding.Slots {
+		if s == slot {
+			return
+		}
+	}
+	call.finding.Slots = append(call.finding.Slots, slot)
+}
@@ -0,0 +1,285 @@
+// Author: Jianbo-Gao
+// Recording taint analysis results of a single execution.
+
+package vm
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Finding describes a single detection made by the taint engine: where it
+// happened, on which concrete values, and how it was classified.
+type Finding struct {
+	Pc         uint64          // program counter of the operation
+	Op         OpCode          // operation that was checked
+	Operands   []*big.Int      // concrete operand values, in stack order
+	Result     *big.Int        // (wrapped) result pushed onto the stack
+	Address    common.Address  // address of the executing contract
+	CodeHash   common.Hash     // hash of the executing code
+	Depth      int             // call depth of the executing frame
+	Flag       int             // taint flags raised by the operation
+	Sources    int             // source labels of the operands
+	Args       [][]TaintArgRef // calldata words of every operand, in stack order
+	Selector   []byte          // function selector of a reentrant call
+	Slots      []common.Hash   // stale slots written after a reentrant call
+	Iterations uint64          // times a tainted loop jumped back
+	GasUsed    uint64          // gas used by a tainted loop
+
+	guard      int    // guard label of an overflowed result
+	guardState int    // whether the result reached a guard
+	guardDepth int    // call depth of the guarding JUMPI
+	words      int    // calldata word labels of the operands
+	sink       int    // most severe sink the wrapped result reached
+	severity   string // severity of a finding without a sink
+}
+
+// Class returns the classification of the finding.
+func (f *Finding) Class() string {
+	return taintClass(f.Flag)
+}
+
+// Labels returns the names of the sources that reached the operation.
+func (f *Finding) Labels() []string {
+	return taintSources(f.Sources)
+}
+
+// Sink returns the most severe sink the wrapped result reached, or an empty
+// string if the operation did not overflow.
+func (f *Finding) Sink() string {
+	return sinkNames[f.sink]
+}
+
+// Severity returns the severity of the sink the wrapped result reached, or
+// the severity set by the detector of the finding.
+func (f *Finding) Severity() string {
+	if f.severity != "" {
+		return f.severity
+	}
+	return sinkSeverities[f.sink]
+}
+
+func (f *Finding) MarshalJSON() ([]byte, error) {
+	type finding struct {
+		Pc         uint64          `json:"pc"`
+		Op         OpCode          `json:"op"`
+		OpName     string          `json:"opName"`
+		Operands   []*hexutil.Big  `json:"operands"`
+		Result     *hexutil.Big    `json:"result"`
+		Address    common.Address  `json:"address"`
+		CodeHash   common.Hash     `json:"codeHash"`
+		Depth      int             `json:"depth"`
+		Class      string          `json:"class"`
+		Sources    []string        `json:"sources"`
+		Args       [][]TaintArgRef `json:"args"`
+		Sink       string          `json:"sink,omitempty"`
+		Severity   string          `json:"severity,omitempty"`
+		Selector   hexutil.Bytes   `json:"selector,omitempty"`
+		Slots      []common.Hash   `json:"slots,omitempty"`
+		Iterations uint64          `json:"iterations,omitempty"`
+		GasUsed    uint64          `json:"gasUsed,omitempty"`
+	}
+	enc := finding{
+		Pc:         f.Pc,
+		Op:         f.Op,
+		OpName:     f.Op.String(),
+		Operands:   make([]*hexutil.Big, len(f.Operands)),
+		Result:     (*hexutil.Big)(f.Result),
+		Address:    f.Address,
+		CodeHash:   f.CodeHash,
+		Depth:      f.Depth,
+		Class:      f.Class(),
+		Sources:    f.Labels(),
+		Args:       f.Args,
+		Sink:       f.Sink(),
+		Severity:   f.Severity(),
+		Selector:   f.Selector,
+		Slots:      f.Slots,
+		Iterations: f.Iterations,
+		GasUsed:    f.GasUsed,
+	}
+	for i, operand := range f.Operands {
+		enc.Operands[i] = (*hexutil.Big)(operand)
+	}
+	return json.Marshal(&enc)
+}
+
+// TaintReport holds the taint analysis verdict of one top-level Call or
+// Create. Every EVM owns its own report, so executions never share results.
+type TaintReport struct {
+	Flag     int        // OR'ed taint flags raised during the execution
+	Findings []*Finding // every detection, in execution order
+
+	layout   *argLayout // arguments of the top-level calldata
+	returned int        // taint flags merged from returned data
+
+	guards    [GUARD_LABELS]*Finding // finding owning each guard label
+	guardAges [GUARD_LABELS]int      // when each guard label was given out
+	labelled  int                    // guard labels given out so far
+
+	candidates  []*Finding // signed overflows not known to be signed yet
+	signedWords int        // calldata words used as signed integers
+
+	reentrancyFrames map[*Contract]*reentrancyFrame // storage accesses of every frame
+	uncheckedCalls   []*uncheckedCall               // calls whose success was not checked yet
+	callOwners       [CALL_LABELS]*uncheckedCall    // unchecked call owning each success label
+	calls            int                            // calls given a success label so far
+	callLabel        int                            // success label of the call about to run
+	loopConds        map[*Contract][]*loopCond      // tainted JUMPIs of every frame
+}
+
+func NewTaintReport() *TaintReport {
+	return &TaintReport{Flag: SAFE_FLAG, layout: &argLayout{}}
+}
+
+// newCallTaintReport returns a report for a top-level call with the given
+// calldata. The args describe the parameters of the called method, if nil
+// they are inferred from the calldata.
+func newCallTaintReport(input []byte, args []TaintArg) *TaintReport {
+	r := NewTaintReport()
+	r.layout = newArgLayout(input, args)
+	return r
+}
+
+// record adds a finding for the operation at pc and merges its flag into
+// the report. The taints are those of the operands, in stack order. The
+// operands and result must not be shared with the stack. It returns the
+// labels of an overflowed result, to be added to the taint of the result.
+func (r *TaintReport) record(evm *EVM, contract *Contract, pc uint64, op OpCode, flag int, taints []int, result *big.Int, operands ...*big.Int) int {
+	f := r.newFinding(evm, contract, pc, op, flag, taints, result, operands...)
+	r.Flag |= flag
+	r.Findings = append(r.Findings, f)
+	return r.wrapped(f)
+}
+
+// wrapped labels the result of a finding that overflowed, the labels are
+// returned. See taint_guard.go and taint_sink.go.
+func (r *TaintReport) wrapped(f *Finding) int {
+	if f.Flag&(OVERFLOW_FLAG|UNDERFLOW_FLAG|SIGNED_OVERFLOW_FLAG) == 0 {
+		return SAFE_FLAG
+	}
+	label := r.guardLabel()
+	if old := r.guards[label]; old != nil {
+		// the result of the old finding is no longer followed
+		old.guard = SAFE_FLAG
+	}
+	r.guards[label], r.guardAges[label] = f, r.labelled
+	r.labelled++
+	f.guard = guardFlag(label)
+	f.guardState = guardPending
+	f.sink = sinkDropped
+	return f.guard | OVERFLOWED_FLAG
+}
+
+// guardLabel returns the index of the guard label for a new overflowed
+// result: a free label, else the oldest label of a finding whose guard
+// verdict is final, else the oldest label.
+func (r *TaintReport) guardLabel() int {
+	label, final := -1, false
+	for i, f := range r.guards {
+		if f == nil {
+			return i
+		}
+		switch done := f.guardState == guardNone; {
+		case label < 0, done && !final, done == final && r.guardAges[i] < r.guardAges[label]:
+			label, final = i, done
+		}
+	}
+	return label
+}
+
+// newFinding describes the operation at pc without recording it.
+func (r *TaintReport) newFinding(evm *EVM, contract *Contract, pc uint64, op OpCode, flag int, taints []int, result *big.Int, operands ...*big.Int) *Finding {
+	var (
+		sources = SAFE_FLAG
+		words   = SAFE_FLAG
+		args    = make([][]TaintArgRef, len(taints))
+	)
+	for i, t := range taints {
+		sources |= t & SOURCE_FLAGS
+		words |= t & CALLDATA_WORD_FLAGS
+		args[i] = r.layout.refs(t)
+	}
+	return &Finding{
+		Pc:       pc,
+		Op:       op,
+		Operands: operands,
+		Result:   result,
+		Address:  contract.Address(),
+		CodeHash: contract.CodeHash,
+		Depth:    evm.depth,
+		Flag:     flag,
+		Sources:  sources,
+		Args:     args,
+		words:    words,
+	}
+}
+
+// merge adds the taint flags of data returned by a frame to the report. The
+// verdicts carried by the data are left out, the report takes them from its
+// findings, which may still change their verdict.
+func (r *TaintReport) merge(flag int) {
+	r.returned |= flag &^ VERDICT_FLAGS
+	r.Flag |= r.returned
+}
+
+// update recomputes the report flag after a finding changed its verdict.
+func (r *TaintReport) update() {
+	r.Flag = r.returned
+	for _, f := range r.Findings {
+		r.Flag |= f.Flag
+	}
+}
+
+// Result returns the most severe classification recorded in the report.
+func (r *TaintReport) Result() string {
+	return taintClass(r.Flag)
+}
+
+// Print writes every finding as a json line, the most severe first,
+// followed by the verdict.
+func (r *TaintReport) Print() {
+	for _, f := range r.Ranked() {
+		if j_data, err := json.Marshal(f); err == nil {
+			fmt.Printf("TaintFinding:%s\n", j_data)
+		}
+	}
+	fmt.Printf("taint flag: %s\n", r.Result())
+}
+
+func taintClass(flag int) string {
+	if flag&OVERFLOW_FLAG > 0 {
+		return "overflow"
+	} else if flag&UNDERFLOW_FLAG > 0 {
+		return "underflow"
+	} else if flag&SIGNED_OVERFLOW_FLAG > 0 {
+		return "signed overflow"
+	} else if flag&REENTRANCY_FLAG > 0 {
+		return "reentrancy"
+	} else if flag&TAINTED_TARGET_FLAG > 0 {
+		return "tainted target"
+	} else if flag&TAINTED_JUMP_FLAG > 0 {
+		return "tainted jump"
+	} else if flag&UNCHECKED_CALL_FLAG > 0 {
+		return "unchecked call"
+	} else if flag&ORIGIN_AUTH_FLAG > 0 {
+		return "tx.origin authorization"
+	} else if flag&PROTECTED_OVERFLOW_FLAG > 0 {
+		return "protected overflow"
+	} else if flag&PROTECTED_UNDERFLOW_FLAG > 0 {
+		return "protected underflow"
+	} else if flag&PROTECTED_SIGNED_OVERFLOW_FLAG > 0 {
+		return "protected signed overflow"
+	} else if flag&POTENTIAL_OVERFLOW_FLAG > 0 {
+		return "potential overflow"
+	} else if flag&POTENTIAL_UNDERFLOW_FLAG > 0 {
+		return "potential underflow"
+	} else if flag&LOOP_BOUND_FLAG > 0 {
+		return "tainted loop bound"
+	}
+	return "safe"
+}
@@ -0,0 +1,44 @@
+// Author: Jianbo-Gao
+// Recognizing the function selector by its data flow.
+
+package vm
+
+import (
+	"math/big"
+)
+
+// The function selector is the first 4 bytes of calldata. Dispatchers load
+// the first calldata word and cut it down to these bytes, e.g.
+//
+//	PUSH1 0 CALLDATALOAD PUSH1 0xe0 SHR                            (solc 0.5+)
+//	PUSH1 0 CALLDATALOAD PUSH29 0x0100..00 SWAP1 DIV PUSH4 0xffffffff AND
+//
+// CALLDATALOAD at offset 0 marks its word with SELECTOR_WORD_FLAG. Shifting
+// or dividing the marked word by 2^224 or more, or masking it to its first
+// 4 bytes, leaves only the selector, which is not treated as tainted. Any
+// other use of the word keeps the taint of the arguments in bytes 4..31.
+const selectorShift = 224
+
+// selectorTaint returns the taint of the selector cut out of a marked word.
+func selectorTaint(flag int) int {
+	return flag &^ (CALLDATA_FLAG | SELECTOR_WORD_FLAG | CALLDATA_WORD_FLAGS)
+}
+
+// isSelectorShift reports whether shifting a word right by shift bits keeps
+// no more than its first 4 bytes.
+func isSelectorShift(shift *big.Int) bool {
+	return shift.Cmp(big.NewInt(selectorShift)) >= 0
+}
+
+// isSelectorDivisor reports whether dividing a word by d equals shifting it
+// right by 224 bits or more.
+func isSelectorDivisor(d *big.Int) bool {
+	n := d.BitLen() - 1
+	return n >= selectorShift && d.TrailingZeroBits() == uint(n)
+}
+
+// isSelectorMask reports whether and-ing a word with mask keeps no more than
+// its first 4 bytes.
+func isSelectorMask(mask *big.Int) bool {
+	return mask.Sign() != 0 && mask.TrailingZeroBits() >= selectorShift
+}
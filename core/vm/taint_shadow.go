@@ -0,0 +1,191 @@
+// Author: Jianbo-Gao
+// Shadowing the machine state with taint.
+
+package vm
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// A taintShadow keeps the taint stack and memory of a frame in step with
+// its stack and memory by applying the rules of taint_table.go. The rule of
+// an operation is evaluated before it runs, on the operands it is about to
+// pop, and applied once it ran without error. Building with the
+// VERIFY_EVM_TAINT_SHADOW tag checks the shadow after every instruction,
+// see taint_verifier.go.
+type taintShadow struct {
+	in       *Interpreter
+	contract *Contract
+	stack    *TaintStack
+	memory   *TaintMemory
+
+	// evaluated before the operation
+	rule        *taintRule
+	push        []int // taint of the pushed items, top first
+	stored      int   // taint of every stored byte
+	storeOffset uint64
+	storeSize   uint64
+	fromOffset  uint64
+	slot        common.Hash
+	returns     []int // taint of the data returned to the caller
+}
+
+func newTaintShadow(in *Interpreter, contract *Contract, taint_stack *TaintStack, taint_mem *TaintMemory) *taintShadow {
+	return &taintShadow{
+		in:       in,
+		contract: contract,
+		stack:    taint_stack,
+		memory:   taint_mem,
+		push:     make([]int, 0, 17),
+	}
+}
+
+// capture evaluates the rule of an operation on the stack before the
+// operation.
+func (s *taintShadow) capture(rule *taintRule, stack *Stack) {
+	s.rule = rule
+
+	loaded := SAFE_FLAG
+	if r := rule.load; r != nil {
+		switch r.buffer {
+		case storageBuffer:
+			loaded = s.in.evm.StateDB.GetStateTaint(s.contract.Address(), common.BigToHash(stack.Back(r.offset)))
+		case memoryBuffer:
+			loaded = s.memory.Union(operand(stack, r.offset), rangeSize(stack, r))
+		default:
+			for _, t := range s.read(r, operand(stack, r.offset), rangeSize(stack, r)) {
+				loaded |= t
+			}
+		}
+	}
+	s.push = s.push[:0]
+	for _, items := range rule.push {
+		t := loaded | rule.label
+		for _, n := range items {
+			t |= s.stack.Back(n)
+		}
+		s.push = append(s.push, t)
+	}
+	if rule.refine != nil {
+		rule.refine(s.in.evm, stack, s.stack, s.push)
+	}
+
+	if r := rule.store; r != nil {
+		if r.buffer == storageBuffer {
+			s.slot = common.BigToHash(stack.Back(r.offset))
+		} else {
+			s.storeOffset, s.storeSize = operand(stack, r.offset), rangeSize(stack, r)
+		}
+		s.stored = SAFE_FLAG
+		for _, n := range rule.stored {
+			s.stored |= s.stack.Back(n)
+		}
+	}
+	if r := rule.from; r != nil {
+		s.fromOffset = operand(stack, r.offset)
+	}
+	if r := rule.args; r != nil {
+		s.in.callTaint = s.read(r, operand(stack, r.offset), rangeSize(stack, r))
+	}
+	if r := rule.returns; r != nil {
+		s.returns = s.read(r, operand(stack, r.offset), rangeSize(stack, r))
+	}
+}
+
+// apply updates the taint stack and memory after the captured operation ran
+// without error. It returns the taint of the data returned to the caller,
+// if the operation returns any.
+func (s *taintShadow) apply() []int {
+	rule := s.rule
+	for i := 0; i < rule.pops; i++ {
+		s.stack.pop()
+	}
+	for i := len(s.push) - 1; i >= 0; i-- {
+		s.stack.push(s.push[i])
+	}
+
+	if r := rule.store; r != nil {
+		switch {
+		case r.buffer == storageBuffer:
+			// later executions have reports and calldata of their own
+			s.in.evm.StateDB.SetStateTaint(s.contract.Address(), s.slot, s.stored&^(REPORT_FLAGS|CALLDATA_WORD_FLAGS))
+		case rule.from != nil:
+			// read now, a call only returns its data while it runs
+			s.memory.Set(s.storeOffset, s.storeSize, s.read(rule.from, s.fromOffset, s.storeSize))
+		default:
+			s.memory.Fill(s.storeOffset, s.storeSize, s.stored)
+		}
+	}
+
+	if rule.returns == nil {
+		return nil
+	}
+	t_ret := SAFE_FLAG
+	for _, t := range s.returns {
+		t_ret |= t
+	}
+	s.in.evm.taintReport.merge(t_ret)
+	return s.returns
+}
+
+// read returns the taint of size bytes at offset of the buffer of r. Return
+// data is not padded, only the bytes returned are read.
+func (s *taintShadow) read(r *taintRange, offset, size uint64) []int {
+	var t_value []int
+	switch r.buffer {
+	case memoryBuffer:
+		t_value = s.memory.Get(int64(offset), int64(size))
+	case calldataBuffer:
+		t_value = getTaint(s.contract.InputTaint, offset, size)
+	case codeBuffer:
+		t_value = make([]int, size)
+	case returnDataBuffer:
+		data := s.in.returnFlag
+		if offset > uint64(len(data)) {
+			offset = uint64(len(data))
+		}
+		if size > uint64(len(data))-offset {
+			size = uint64(len(data)) - offset
+		}
+		t_value = data[offset : offset+size]
+	}
+	if r.label != SAFE_FLAG {
+		t_value = labelTaint(t_value, r.label)
+	}
+	return t_value
+}
+
+// operand returns the stack item n as an offset or size, saturated to the
+// largest uint64. noOperand is zero.
+func operand(stack *Stack, n int) uint64 {
+	if n == noOperand {
+		return 0
+	}
+	if v := stack.Back(n); v.IsUint64() {
+		return v.Uint64()
+	}
+	return math.MaxUint64
+}
+
+// rangeSize returns the size of the range r on the stack.
+func rangeSize(stack *Stack, r *taintRange) uint64 {
+	if r.size == noOperand {
+		return r.length
+	}
+	return operand(stack, r.size)
+}
+
+// checkTaintShadow returns an error if the taint stack or memory does not
+// have the size of the stack or memory after the operation op at pc.
+func checkTaintShadow(op OpCode, pc uint64, stack *Stack, mem *Memory, taint_stack *TaintStack, taint_mem *TaintMemory) error {
+	if taint_stack.len() != stack.len() {
+		return fmt.Errorf("taint shadow: %v at pc %d left %d taint stack items for %d stack items", op, pc, taint_stack.len(), stack.len())
+	}
+	if taint_mem.Len() != mem.Len() {
+		return fmt.Errorf("taint shadow: %v at pc %d left %d taint memory bytes for %d memory bytes", op, pc, taint_mem.Len(), mem.Len())
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+// Author: Jianbo-Gao
+// Tests for the taint shadow of every opcode.
+
+package vm
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestTaintShadow(t *testing.T) {
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	ctx := Context{
+		CanTransfer: func(StateDB, common.Address, *big.Int) bool { return true },
+		Transfer:    func(StateDB, common.Address, common.Address, *big.Int) {},
+		GetHash:     func(uint64) common.Hash { return common.Hash{} },
+		GasPrice:    new(big.Int),
+		BlockNumber: new(big.Int),
+		Time:        new(big.Int),
+		Difficulty:  new(big.Int),
+	}
+	for i, operation := range newTaintInstructionSet(constantinopleInstructionSet) {
+		op := OpCode(i)
+		if !operation.valid {
+			continue
+		}
+		if operation.taint == nil {
+			t.Errorf("%v: no taint rule", op)
+			continue
+		}
+		var (
+			env         = NewEVM(ctx, statedb, params.TestChainConfig, Config{TaintAnalysis: true})
+			contract    = NewContract(AccountRef(common.Address{1}), AccountRef(common.Address{2}), new(big.Int), 100000)
+			stack       = newstack()
+			taint_stack = newtaintstack()
+			mem         = NewMemory()
+			taint_mem   = NewTaintMemory()
+			pc          = uint64(0)
+		)
+		// zero operands are valid for every operation, the code jumps to
+		// its first byte
+		contract.Code = []byte{byte(JUMPDEST)}
+		for n := 0; n < 17; n++ {
+			stack.push(new(big.Int))
+			taint_stack.push(CALLDATA_FLAG)
+		}
+		if operation.memorySize != nil {
+			size := toWordSize(operation.memorySize(stack).Uint64()) * 32
+			mem.Resize(size)
+			taint_mem.Resize(size)
+		}
+		shadow := newTaintShadow(env.interpreter, contract, taint_stack, taint_mem)
+		shadow.capture(operation.taint, stack)
+		if _, _, err := operation.execute(&pc, env, contract, mem, stack); err != nil {
+			t.Errorf("%v: didn't expect error: %v", op, err)
+			continue
+		}
+		shadow.apply()
+		if err := checkTaintShadow(op, 0, stack, mem, taint_stack, taint_mem); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestTaintTable(t *testing.T) {
+	tests := []struct {
+		op     OpCode
+		stack  []int // taint of the operands, top first
+		values []int64
+		want   []int // taint stack after the operation, top first
+	}{
+		{ADD, []int{CALLDATA_FLAG, CALLER_FLAG}, []int64{1, 2}, []int{CALLDATA_FLAG | CALLER_FLAG}},
+		{SWAP2, []int{CALLDATA_FLAG, SAFE_FLAG, CALLER_FLAG}, []int64{1, 2, 3}, []int{CALLER_FLAG, SAFE_FLAG, CALLDATA_FLAG}},
+		{DUP2, []int{SAFE_FLAG, CALLER_FLAG}, []int64{1, 2}, []int{CALLER_FLAG, SAFE_FLAG, CALLER_FLAG}},
+		{ADDMOD, []int{CALLDATA_FLAG, SAFE_FLAG, CALLER_FLAG}, []int64{1, 2, 0}, []int{CALLER_FLAG}},
+		{ORIGIN, nil, nil, []int{ORIGIN_FLAG}},
+	}
+	for i, test := range tests {
+		var (
+			env         = NewEVM(Context{}, nil, params.TestChainConfig, Config{TaintAnalysis: true})
+			operation   = newTaintInstructionSet(constantinopleInstructionSet)[test.op]
+			contract    = NewContract(AccountRef(common.Address{1}), AccountRef(common.Address{2}), new(big.Int), 0)
+			stack       = newstack()
+			taint_stack = newtaintstack()
+			pc          = uint64(0)
+		)
+		for n := len(test.values) - 1; n >= 0; n-- {
+			stack.push(big.NewInt(test.values[n]))
+			taint_stack.push(test.stack[n])
+		}
+		shadow := newTaintShadow(env.interpreter, contract, taint_stack, NewTaintMemory())
+		shadow.capture(operation.taint, stack)
+		if _, _, err := operation.execute(&pc, env, contract, NewMemory(), stack); err != nil {
+			t.Fatalf("test %d: %v: didn't expect error: %v", i, test.op, err)
+		}
+		shadow.apply()
+		if taint_stack.len() != len(test.want) {
+			t.Fatalf("test %d: %v: taint stack has %d items, want %d", i, test.op, taint_stack.len(), len(test.want))
+		}
+		for n, want := range test.want {
+			if got := taint_stack.Back(n); got != want {
+				t.Errorf("test %d: %v: taint of item %d = %#x, want %#x", i, test.op, n, got, want)
+			}
+		}
+	}
+}
+
+func TestTaintAnalysisDisabled(t *testing.T) {
+	// returns the calldata word at offset 0 plus one
+	code := common.Hex2Bytes("60003560010160005260206000f3")
+	input := common.LeftPadBytes([]byte{41}, 32)
+	ctx := Context{
+		CanTransfer: func(StateDB, common.Address, *big.Int) bool { return true },
+		Transfer:    func(StateDB, common.Address, common.Address, *big.Int) {},
+		BlockNumber: new(big.Int),
+	}
+	for _, analysis := range []bool{false, true} {
+		if verifyTaint && !analysis {
+			// the verifier build always runs the analysis
+			continue
+		}
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+		statedb.SetCode(common.Address{2}, code)
+		env := NewEVM(ctx, statedb, params.TestChainConfig, Config{TaintAnalysis: analysis})
+		if selected := env.interpreter.cfg.JumpTable[STOP].taint != nil; selected != analysis {
+			t.Errorf("analysis %v: taint rules selected: %v", analysis, selected)
+		}
+		// the instruction set is the one of the chain rules
+		if env.interpreter.cfg.JumpTable[SHL].valid {
+			t.Errorf("analysis %v: constantinople instruction set selected before the fork", analysis)
+		}
+		ret, taintFlag, _, err := env.Call(AccountRef(common.Address{1}), common.Address{2}, input, nil, 100000, new(big.Int))
+		if err != nil {
+			t.Fatalf("analysis %v: didn't expect error: %v", analysis, err)
+		}
+		if new(big.Int).SetBytes(ret).Int64() != 42 {
+			t.Errorf("analysis %v: expected 42, got %x", analysis, ret)
+		}
+		if tainted := len(taintFlag) == 32 && taintFlag[31]&CALLDATA_FLAG > 0; tainted != analysis {
+			t.Errorf("analysis %v: return data taint %v", analysis, taintFlag)
+		}
+	}
+}
+
+func TestTaintConstantinopleOpcodes(t *testing.T) {
+	var (
+		caller = common.Address{2}
+		other  = common.BytesToAddress([]byte{3})
+		code   = common.Hex2Bytes("6060")
+	)
+	tests := []struct {
+		code    string // returns the word pushed by the operation
+		input   []byte
+		want    common.Hash
+		tainted bool
+	}{
+		// CREATE2 with the salt from calldata
+		{"600035600060006000f560005260206000f3", common.LeftPadBytes([]byte{7}, 32),
+			crypto.CreateAddress2(caller, common.BigToHash(big.NewInt(7)), crypto.Keccak256(nil)).Hash(), true},
+		// CREATE2 with the init code copied from calldata
+		{"600160006000376000600160006000f560005260206000f3", []byte{byte(STOP)},
+			crypto.CreateAddress2(caller, common.Hash{}, crypto.Keccak256([]byte{byte(STOP)})).Hash(), true},
+		{"6000600060006000f560005260206000f3", nil,
+			crypto.CreateAddress2(caller, common.Hash{}, crypto.Keccak256(nil)).Hash(), false},
+		// CREATE with the init code copied from calldata
+		{"60016000600037600160006000f060005260206000f3", []byte{byte(STOP)},
+			crypto.CreateAddress(caller, 0).Hash(), true},
+		{"600060006000f060005260206000f3", nil, crypto.CreateAddress(caller, 0).Hash(), false},
+		// EXTCODEHASH of the address from calldata
+		{"6000353f60005260206000f3", common.LeftPadBytes(other.Bytes(), 32), crypto.Keccak256Hash(code), true},
+		{"6000353f60005260206000f3", common.LeftPadBytes([]byte{4}, 32), common.Hash{}, true},
+		{"60033f60005260206000f3", nil, crypto.Keccak256Hash(code), false},
+	}
+	ctx := Context{
+		CanTransfer: func(StateDB, common.Address, *big.Int) bool { return true },
+		Transfer:    func(StateDB, common.Address, common.Address, *big.Int) {},
+		BlockNumber: new(big.Int),
+	}
+	config := *params.TestChainConfig
+	config.ConstantinopleBlock = new(big.Int)
+	for i, test := range tests {
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+		statedb.SetCode(caller, common.Hex2Bytes(test.code))
+		statedb.SetCode(other, code)
+		env := NewEVM(ctx, statedb, &config, Config{TaintAnalysis: true})
+		ret, taintFlag, _, err := env.Call(AccountRef(common.Address{1}), caller, test.input, nil, 100000, new(big.Int))
+		if err != nil {
+			t.Fatalf("test %d: didn't expect error: %v", i, err)
+		}
+		if common.BytesToHash(ret) != test.want {
+			t.Errorf("test %d: got %x, want %x", i, ret, test.want)
+		}
+		t_ret := SAFE_FLAG
+		for _, t := range taintFlag {
+			t_ret |= t
+		}
+		if tainted := t_ret&CALLDATA_FLAG > 0; tainted != test.tainted {
+			t.Errorf("test %d: got taint %#x, want tainted %v", i, t_ret, test.tainted)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+// Author: Jianbo-Gao
+// Detecting signed integer overflow.
+
+package vm
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+)
+
+// A word is only known to hold a signed integer by the way it is used. The
+// results of SDIV, SMOD, SIGNEXTEND and SAR are labelled with SIGNED_FLAG,
+// and every value compared with SLT/SGT or fed to SAR, SIGNEXTEND, SDIV or
+// SMOD is a signed use: the calldata words it is derived from are signed
+// from then on.
+//
+// Arithmetic on tainted values whose signed result does not fit in 256 bits
+// is recorded as a candidate. The candidate becomes a signed overflow
+// finding as soon as an operand is known to be signed, either at once or
+// when the operands' calldata words or the result are used as signed later.
+
+var (
+	tt255m1 = new(big.Int).Sub(tt255, common.Big1)
+	ttm255  = new(big.Int).Neg(tt255)
+)
+
+// signedOverflow reports whether the signed value x does not fit in 256 bits.
+func signedOverflow(x *big.Int) bool {
+	return x.Cmp(tt255m1) > 0 || x.Cmp(ttm255) < 0
+}
+
+func checkSignedAddOverflow(x, y *big.Int) bool {
+	return signedOverflow(new(big.Int).Add(math.S256(new(big.Int).Set(x)), math.S256(new(big.Int).Set(y))))
+}
+
+func checkSignedSubOverflow(x, y *big.Int) bool {
+	return signedOverflow(new(big.Int).Sub(math.S256(new(big.Int).Set(x)), math.S256(new(big.Int).Set(y))))
+}
+
+func checkSignedMulOverflow(x, y *big.Int) bool {
+	return signedOverflow(new(big.Int).Mul(math.S256(new(big.Int).Set(x)), math.S256(new(big.Int).Set(y))))
+}
+
+// checkSignedDivOverflow reports whether x / y, or x % y, is -2^255 / -1.
+func checkSignedDivOverflow(x, y *big.Int) bool {
+	return x.Cmp(tt255) == 0 && y.Cmp(math.MaxBig256) == 0
+}
+
+// checkSignExtendOverflow reports whether extending the sign of the value
+// changes it, that is it does not fit in the narrower signed type.
+func checkSignExtendOverflow(value, result *big.Int) bool {
+	return value.Cmp(result) != 0
+}
+
+// recordSigned records a signed overflow of a tainted operation. The finding
+// is a candidate until an operand is known to be signed, unless signed is
+// set. It returns the labels of the result, like record.
+func (r *TaintReport) recordSigned(evm *EVM, contract *Contract, pc uint64, op OpCode, signed bool, taints []int, result *big.Int, operands ...*big.Int) int {
+	for _, t := range taints {
+		if t&SIGNED_FLAG > 0 || t&r.signedWords > 0 {
+			signed = true
+		}
+	}
+	if signed {
+		return r.record(evm, contract, pc, op, SIGNED_OVERFLOW_FLAG, taints, result, operands...)
+	}
+	f := r.newFinding(evm, contract, pc, op, SIGNED_OVERFLOW_FLAG, taints, result, operands...)
+	r.candidates = append(r.candidates, f)
+	return r.wrapped(f)
+}
+
+// signedUse is called for a value with the given taint used as a signed
+// integer. Candidates derived from the same calldata words or leading to
+// the value become findings.
+func (r *TaintReport) signedUse(taint int) {
+	r.signedWords |= taint & CALLDATA_WORD_FLAGS
+	if len(r.candidates) == 0 {
+		return
+	}
+	candidates := r.candidates[:0]
+	for _, f := range r.candidates {
+		if f.words&r.signedWords > 0 || taint&f.guard > 0 {
+			r.Findings = append(r.Findings, f)
+			r.Flag |= f.Flag
+		} else {
+			candidates = append(candidates, f)
+		}
+	}
+	r.candidates = candidates
+}
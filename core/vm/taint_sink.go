@@ -0,0 +1,61 @@
+// Author: Jianbo-Gao
+// Ranking overflows by where the wrapped value ends up.
+
+package vm
+
+import (
+	"sort"
+)
+
+// The wrapped result of an overflow is labelled with OVERFLOWED_FLAG next to
+// the guard label of its finding. Whenever a value carrying both reaches a
+// sink, the finding is ranked by the most severe sink seen: persisted by
+// SSTORE, moved as the value or recipient of a CALL, emitted by a LOG,
+// returned to the caller of the transaction, or dropped.
+
+// Sinks of an overflowed value, from the least to the most severe.
+const (
+	sinkNone    = iota // the finding did not overflow
+	sinkDropped        // the value was discarded
+	sinkReturn         // returned by the outermost frame
+	sinkLog            // data or topic of an event
+	sinkCall           // value or recipient of a call
+	sinkStore          // written to storage
+)
+
+var sinkNames = []string{"", "dropped", "return", "log", "call", "sstore"}
+
+var sinkSeverities = []string{"", "info", "low", "medium", "critical", "critical"}
+
+// sink is called for a value with the given taint reaching a sink of the
+// given kind.
+func (r *TaintReport) sink(kind int, taint int) {
+	if taint&OVERFLOWED_FLAG == 0 {
+		return
+	}
+	for _, findings := range [][]*Finding{r.Findings, r.candidates} {
+		for _, f := range findings {
+			if f.sink != sinkNone && f.sink < kind && taint&f.guard > 0 {
+				f.sink = kind
+			}
+		}
+	}
+}
+
+// severityRanks orders the severities, from the least to the most severe.
+var severityRanks = map[string]int{"": 0, "info": 1, "low": 2, "medium": 3, "high": 4, "critical": 5}
+
+// Ranked returns the findings ordered by their severity, most severe first,
+// then by the severity of their sinks, and by execution order otherwise.
+func (r *TaintReport) Ranked() []*Finding {
+	findings := make([]*Finding, len(r.Findings))
+	copy(findings, r.Findings)
+	sort.SliceStable(findings, func(i, j int) bool {
+		si, sj := severityRanks[findings[i].Severity()], severityRanks[findings[j].Severity()]
+		if si != sj {
+			return si > sj
+		}
+		return findings[i].sink > findings[j].sink
+	})
+	return findings
+}
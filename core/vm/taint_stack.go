@@ -38,15 +38,6 @@ func (st *TaintStack) len() int {
 	return len(st.data)
 }
 
-func (st *TaintStack) swap(n int) {
-	st.data[st.len()-n], st.data[st.len()-1] = st.data[st.len()-1], st.data[st.len()-n]
-}
-
-func (st *TaintStack) dup(pool *TaintIntPool, n int) {
-	pool.get()
-	st.push(st.data[st.len()-n])
-}
-
 func (st *TaintStack) peek() int {
 	return st.data[st.len()-1]
 }
@@ -56,6 +47,11 @@ func (st *TaintStack) Back(n int) int {
 	return st.data[st.len()-n-1]
 }
 
+// Set replaces the taint of the n'th item in stack
+func (st *TaintStack) Set(n int, flag int) {
+	st.data[st.len()-n-1] = flag
+}
+
 func (st *TaintStack) require(n int) error {
 	if st.len() < n {
 		return fmt.Errorf("stack underflow (%d <=> %d)", len(st.data), n)
@@ -0,0 +1,287 @@
+// Author: Jianbo-Gao
+// Describing the taint propagation of every opcode.
+
+package vm
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// The opcode handlers of instructions.go only compute the machine state. How
+// an operation moves taint is given by its rule in the taintTable: which
+// stack items flow into the items it pushes, which bytes it loads, stores or
+// copies, which labels it adds as a source of taint, and which bytes leave
+// the frame as the input of a callee or the data returned to the caller.
+// The rules are applied by a taintShadow, see taint_shadow.go. A new opcode
+// or a change of policy only needs a change of its rule.
+//
+// Stack items are numbered by their position before the operation, as for
+// Stack.Back.
+
+// Buffers addressed by a taint range.
+const (
+	memoryBuffer     = iota // memory of the frame
+	calldataBuffer          // input of the frame, padded with untainted bytes
+	codeBuffer              // code of a contract, never tainted
+	returnDataBuffer        // data returned by the last call of the frame
+	storageBuffer           // storage slot of the contract, a single taint
+)
+
+// noOperand stands for an offset of zero.
+const noOperand = -1
+
+// taintRange is a range of bytes of a buffer, addressed by the operands of
+// an operation.
+type taintRange struct {
+	buffer int
+	offset int    // stack item holding the offset, or the storage slot
+	size   int    // stack item holding the size, noOperand for length bytes
+	length uint64 // fixed size of the range
+	label  int    // label added to every byte read from the range
+}
+
+// taintRefineFunc adjusts the taint of the pushed items, top first, for
+// operations whose propagation depends on the operand values. It is called
+// before the operation with the stack and taint stack it runs on.
+type taintRefineFunc func(evm *EVM, stack *Stack, taint_stack *TaintStack, push []int)
+
+// taintRule describes how an operation propagates taint.
+type taintRule struct {
+	pops   int             // number of stack items popped
+	push   [][]int         // stack items flowing into each pushed item, top first
+	label  int             // source label added to every pushed item
+	load   *taintRange     // bytes flowing into every pushed item
+	refine taintRefineFunc // value dependent adjustment of the pushed items
+
+	store  *taintRange // bytes written, to memory or storage
+	stored []int       // stack items flowing into every stored byte
+	from   *taintRange // bytes copied to the store range instead, as many as it holds
+
+	args    *taintRange // bytes handed to the callee as input
+	returns *taintRange // bytes returned to the caller and merged into the report
+}
+
+var taintTable = newTaintTable()
+
+// newTaintInstructionSet returns the instruction set with the taint rule of
+// every valid operation, it is used with Config.TaintAnalysis.
+func newTaintInstructionSet(set [256]operation) [256]operation {
+	for op := range set {
+		if set[op].valid {
+			set[op].taint = &taintTable[op]
+		}
+	}
+	return set
+}
+
+// flow returns the push of a single item the given stack items flow into.
+func flow(items ...int) [][]int {
+	return [][]int{items}
+}
+
+// memoryRange returns the memory range at the offset with the size held by
+// the given stack items.
+func memoryRange(offset, size int) *taintRange {
+	return &taintRange{buffer: memoryBuffer, offset: offset, size: size}
+}
+
+// memoryBytes returns the memory range of length bytes at the offset held by
+// the given stack item.
+func memoryBytes(offset int, length uint64) *taintRange {
+	return &taintRange{buffer: memoryBuffer, offset: offset, size: noOperand, length: length}
+}
+
+// callRule returns the rule of a call taking its input and output ranges from
+// the stack items at args; CALL and CALLCODE also pop the value.
+func callRule(pops, args int) taintRule {
+	return taintRule{
+		pops:   pops,
+		push:   flow(),
+		label:  CALL_SUCCESS_FLAG, // see taint_unchecked.go
+		refine: callSuccess,
+		args:   memoryRange(args, args+1),
+		store:  memoryRange(args+2, args+3),
+		from:   &taintRange{buffer: returnDataBuffer, offset: noOperand, label: RETURNDATA_FLAG},
+	}
+}
+
+// swapRule returns the rule of SWAPn, which exchanges the top item and the
+// item n below it.
+func swapRule(n int) taintRule {
+	push := make([][]int, n+1)
+	push[0], push[n] = []int{n}, []int{0}
+	for i := 1; i < n; i++ {
+		push[i] = []int{i}
+	}
+	return taintRule{pops: n + 1, push: push}
+}
+
+func newTaintTable() [256]taintRule {
+	table := [256]taintRule{
+		STOP: {},
+
+		// arithmetic keeps the taint of its operands, overflows are checked by
+		// the overflow detector, see taint_overflow.go
+		ADD:        {pops: 2, push: flow(0, 1)},
+		MUL:        {pops: 2, push: flow(0, 1)},
+		SUB:        {pops: 2, push: flow(0, 1)},
+		DIV:        {pops: 2, push: flow(0, 1), refine: selectorDiv},
+		SDIV:       {pops: 2, push: flow(0, 1)},
+		MOD:        {pops: 2, push: flow(0, 1)},
+		SMOD:       {pops: 2, push: flow(0, 1)},
+		ADDMOD:     {pops: 3, push: flow(0, 1), refine: zeroModulus},
+		MULMOD:     {pops: 3, push: flow(0, 1), refine: zeroModulus},
+		EXP:        {pops: 2, push: flow(0, 1)},
+		SIGNEXTEND: {pops: 2, push: flow(0, 1)},
+
+		LT:     {pops: 2, push: flow(0, 1)},
+		GT:     {pops: 2, push: flow(0, 1)},
+		SLT:    {pops: 2, push: flow(0, 1)},
+		SGT:    {pops: 2, push: flow(0, 1)},
+		EQ:     {pops: 2, push: flow(0, 1)},
+		ISZERO: {pops: 1, push: flow(0)},
+		AND:    {pops: 2, push: flow(0, 1), refine: selectorAnd},
+		OR:     {pops: 2, push: flow(0, 1)},
+		XOR:    {pops: 2, push: flow(0, 1)},
+		NOT:    {pops: 1, push: flow(0)},
+		BYTE:   {pops: 2, push: flow(0, 1)},
+		SHL:    {pops: 2, push: flow(0, 1)},
+		SHR:    {pops: 2, push: flow(0, 1), refine: selectorShr},
+		SAR:    {pops: 2, push: flow(0, 1)},
+
+		SHA3: {pops: 2, push: flow(), load: memoryRange(0, 1)},
+
+		ADDRESS:        {push: flow()},
+		BALANCE:        {pops: 1, push: flow(0)},
+		ORIGIN:         {push: flow(), label: ORIGIN_FLAG},
+		CALLER:         {push: flow(), label: CALLER_FLAG},
+		CALLVALUE:      {push: flow(), label: CALLVALUE_FLAG},
+		CALLDATALOAD:   {pops: 1, push: flow(), load: &taintRange{buffer: calldataBuffer, offset: 0, size: noOperand, length: 32}, refine: selectorWord},
+		CALLDATASIZE:   {push: flow()},
+		CALLDATACOPY:   {pops: 3, store: memoryRange(0, 2), from: &taintRange{buffer: calldataBuffer, offset: 1}},
+		CODESIZE:       {push: flow()},
+		CODECOPY:       {pops: 3, store: memoryRange(0, 2), from: &taintRange{buffer: codeBuffer, offset: 1}},
+		GASPRICE:       {push: flow()},
+		EXTCODESIZE:    {pops: 1, push: flow()},
+		EXTCODECOPY:    {pops: 4, store: memoryRange(1, 3), from: &taintRange{buffer: codeBuffer, offset: 2}},
+		RETURNDATASIZE: {push: flow()},
+		RETURNDATACOPY: {pops: 3, store: memoryRange(0, 2), from: &taintRange{buffer: returnDataBuffer, offset: 1, label: RETURNDATA_FLAG}},
+		EXTCODEHASH:    {pops: 1, push: flow(0)},
+
+		BLOCKHASH:  {pops: 1, push: flow(0), label: BLOCKHASH_FLAG, refine: unknownBlockhash},
+		COINBASE:   {push: flow(), label: COINBASE_FLAG},
+		TIMESTAMP:  {push: flow(), label: TIMESTAMP_FLAG},
+		NUMBER:     {push: flow(), label: NUMBER_FLAG},
+		DIFFICULTY: {push: flow()},
+		GASLIMIT:   {push: flow()},
+
+		POP:      {pops: 1},
+		MLOAD:    {pops: 1, push: flow(), load: memoryBytes(0, 32)},
+		MSTORE:   {pops: 2, store: memoryBytes(0, 32), stored: []int{1}},
+		MSTORE8:  {pops: 2, store: memoryBytes(0, 1), stored: []int{1}},
+		SLOAD:    {pops: 1, push: flow(), load: &taintRange{buffer: storageBuffer, offset: 0}, refine: storedTaint},
+		SSTORE:   {pops: 2, store: &taintRange{buffer: storageBuffer, offset: 0}, stored: []int{1}},
+		JUMP:     {pops: 1},
+		JUMPI:    {pops: 2},
+		PC:       {push: flow()},
+		MSIZE:    {push: flow()},
+		GAS:      {push: flow()},
+		JUMPDEST: {},
+
+		// the address is zero if the init code fails
+		CREATE:       {pops: 3, push: flow(2), load: memoryRange(1, 2)},
+		CALL:         callRule(7, 3),
+		CALLCODE:     callRule(7, 3),
+		RETURN:       {pops: 2, returns: memoryRange(0, 1)},
+		DELEGATECALL: callRule(6, 2),
+		// the address is derived from the salt and the init code
+		CREATE2:      {pops: 4, push: flow(3), load: memoryRange(1, 2)},
+		STATICCALL:   callRule(6, 2),
+		REVERT:       {pops: 2, returns: memoryRange(0, 1)},
+		SELFDESTRUCT: {pops: 1},
+	}
+	for i := 0; i < 32; i++ {
+		table[PUSH1+OpCode(i)] = taintRule{push: flow()}
+	}
+	for i := 0; i < 16; i++ {
+		table[DUP1+OpCode(i)] = taintRule{push: flow(i)}
+		table[SWAP1+OpCode(i)] = swapRule(i + 1)
+	}
+	for i := 0; i <= 4; i++ {
+		table[LOG0+OpCode(i)] = taintRule{pops: 2 + i}
+	}
+	return table
+}
+
+// selectorDiv leaves the selector divided out of the first calldata word
+// untainted, see taint_selector.go.
+func selectorDiv(evm *EVM, stack *Stack, taint_stack *TaintStack, push []int) {
+	tx, ty := taint_stack.Back(0), taint_stack.Back(1)
+	if isSelectorDivisor(stack.Back(1)) && tx&SELECTOR_WORD_FLAG > 0 && ty == SAFE_FLAG {
+		push[0] = selectorTaint(tx)
+	}
+}
+
+// selectorAnd leaves the selector masked out of the first calldata word
+// untainted.
+func selectorAnd(evm *EVM, stack *Stack, taint_stack *TaintStack, push []int) {
+	tx, ty := taint_stack.Back(0), taint_stack.Back(1)
+	if isSelectorMask(stack.Back(1)) && tx&SELECTOR_WORD_FLAG > 0 && ty == SAFE_FLAG {
+		push[0] = selectorTaint(tx)
+	} else if isSelectorMask(stack.Back(0)) && ty&SELECTOR_WORD_FLAG > 0 && tx == SAFE_FLAG {
+		push[0] = selectorTaint(ty)
+	}
+}
+
+// selectorShr leaves the selector shifted out of the first calldata word
+// untainted.
+func selectorShr(evm *EVM, stack *Stack, taint_stack *TaintStack, push []int) {
+	tx, ty := taint_stack.Back(0), taint_stack.Back(1)
+	if isSelectorShift(stack.Back(0)) && ty&SELECTOR_WORD_FLAG > 0 && tx == SAFE_FLAG {
+		push[0] = selectorTaint(ty)
+	}
+}
+
+// selectorWord marks the word loaded from offset 0 of the calldata, which
+// holds the function selector.
+func selectorWord(evm *EVM, stack *Stack, taint_stack *TaintStack, push []int) {
+	if stack.Back(0).Sign() == 0 && push[0]&CALLDATA_FLAG > 0 {
+		push[0] |= SELECTOR_WORD_FLAG
+	}
+}
+
+// zeroModulus gives ADDMOD and MULMOD by zero, which push zero, the taint of
+// the modulus.
+func zeroModulus(evm *EVM, stack *Stack, taint_stack *TaintStack, push []int) {
+	if stack.Back(2).Sign() == 0 {
+		push[0] = taint_stack.Back(2)
+	}
+}
+
+// unknownBlockhash leaves the zero pushed for blocks out of range untainted.
+func unknownBlockhash(evm *EVM, stack *Stack, taint_stack *TaintStack, push []int) {
+	num := stack.Back(0)
+	n := new(big.Int).Sub(evm.BlockNumber, common.Big257)
+	if num.Cmp(n) <= 0 || num.Cmp(evm.BlockNumber) >= 0 {
+		push[0] = SAFE_FLAG
+	}
+}
+
+// callSuccess labels the success flag of a call with the label the unchecked
+// call detector gave the call, if any.
+func callSuccess(evm *EVM, stack *Stack, taint_stack *TaintStack, push []int) {
+	if label := evm.taintReport.callLabel; label != SAFE_FLAG {
+		push[0] = push[0]&^CALL_SUCCESS_FLAG | label
+		evm.taintReport.callLabel = SAFE_FLAG
+	}
+}
+
+// storedTaint labels a loaded value with STORAGE_FLAG if it was stored
+// tainted.
+func storedTaint(evm *EVM, stack *Stack, taint_stack *TaintStack, push []int) {
+	if push[0]&SOURCE_FLAGS > 0 {
+		push[0] |= STORAGE_FLAG
+	}
+}
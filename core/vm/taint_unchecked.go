@@ -0,0 +1,145 @@
+// Author: Jianbo-Gao
+// Detecting calls whose success is never checked.
+
+package vm
+
+import (
+	"math/big"
+)
+
+// The success flag pushed by CALL, CALLCODE, DELEGATECALL and STATICCALL
+// carries a call success label of its own. The call is checked once a JUMPI
+// of the frame branches on a condition carrying the label, as
+// require(to.send(x)) does, or once the frame returns the label to its
+// caller. A frame that stops or returns with calls left unchecked, e.g.
+// after
+//
+//	a.send(amount);
+//	require(b.send(amount));
+//
+// records a finding for each of them. A failed or reverted frame undoes its
+// calls, they are not reported.
+//
+// There are only CALL_LABELS labels. A new call takes a free label, else the
+// label of the oldest unchecked call. The call losing its label is checked
+// by the success of any call of its frame reaching a JUMPI.
+
+// uncheckedCallDetector records calls whose success is never checked.
+type uncheckedCallDetector struct{}
+
+// NewUncheckedCallDetector returns the unchecked call detector.
+func NewUncheckedCallDetector() Detector {
+	return uncheckedCallDetector{}
+}
+
+// uncheckedCall is a call whose success was not checked yet.
+type uncheckedCall struct {
+	contract *Contract // frame that made the call
+	pc       uint64
+	op       OpCode
+	label    int // call success label, zero once the label is taken over
+	age      int // when the label was given out
+	taints   []int
+	operands []*big.Int
+}
+
+func (uncheckedCallDetector) CaptureOp(step *DetectorStep) DetectorResult {
+	r := step.Report()
+	switch op := step.Op; op {
+	case CALL, CALLCODE, DELEGATECALL, STATICCALL:
+		// gas, address and the value sent, if any
+		n := 2
+		if op == CALL || op == CALLCODE {
+			n = 3
+		}
+		call := &uncheckedCall{contract: step.Contract, pc: step.Pc, op: op}
+		for i := 0; i < n; i++ {
+			call.taints = append(call.taints, step.TaintStack.Back(i))
+			call.operands = append(call.operands, step.Operand(i))
+		}
+		// labelled now, the calls of nested frames take labels of their own
+		r.labelCall(call)
+		return func(step *DetectorStep) {
+			r.uncheckedCalls = append(r.uncheckedCalls, call)
+		}
+
+	case JUMPI:
+		r.checkCalls(step.Contract, step.TaintStack.Back(1))
+
+	case RETURN:
+		// the caller decides whether to check the returned flags
+		r.checkCalls(step.Contract, step.TaintMemory.Union(step.Stack.Back(0).Uint64(), step.Stack.Back(1).Uint64()))
+		r.uncheckedHalt(step)
+
+	case STOP, SELFDESTRUCT:
+		r.uncheckedHalt(step)
+	}
+	return nil
+}
+
+// labelCall gives the call a free success label, else the label of the
+// oldest unchecked call. The label is pushed by callSuccess.
+func (r *TaintReport) labelCall(call *uncheckedCall) {
+	label := -1
+	for i, owner := range r.callOwners {
+		if callSuccessFlag(i) == SAFE_FLAG {
+			break
+		}
+		if owner == nil {
+			label = i
+			break
+		}
+		if label < 0 || owner.age < r.callOwners[label].age {
+			label = i
+		}
+	}
+	if old := r.callOwners[label]; old != nil {
+		old.label = SAFE_FLAG
+	}
+	r.callOwners[label] = call
+	call.label, call.age = callSuccessFlag(label), r.calls
+	r.calls++
+	r.callLabel = call.label
+}
+
+// checkCalls marks the calls made by the frame running contract whose
+// success flags reach a condition or returned data with the given taint as
+// checked. Calls without a label are checked by the success of any call.
+func (r *TaintReport) checkCalls(contract *Contract, taint int) {
+	if taint&CALL_SUCCESS_FLAGS == 0 {
+		return
+	}
+	calls := r.uncheckedCalls[:0]
+	for _, call := range r.uncheckedCalls {
+		if call.contract == contract && (call.label == SAFE_FLAG || taint&call.label > 0) {
+			r.freeCall(call)
+			continue
+		}
+		calls = append(calls, call)
+	}
+	r.uncheckedCalls = calls
+}
+
+// freeCall frees the success label of a call that is checked or reported.
+func (r *TaintReport) freeCall(call *uncheckedCall) {
+	for i, owner := range r.callOwners {
+		if owner == call {
+			r.callOwners[i] = nil
+		}
+	}
+}
+
+// uncheckedHalt records the calls left unchecked by the halting frame of
+// the step.
+func (r *TaintReport) uncheckedHalt(step *DetectorStep) {
+	calls := r.uncheckedCalls[:0]
+	for _, call := range r.uncheckedCalls {
+		if call.contract != step.Contract {
+			calls = append(calls, call)
+			continue
+		}
+		r.record(step.EVM, step.Contract, call.pc, call.op, UNCHECKED_CALL_FLAG, call.taints, nil, call.operands...)
+		r.freeCall(call)
+	}
+	r.uncheckedCalls = calls
+}
@@ -0,0 +1,16 @@
+// Author: Jianbo-Gao
+// Verifying the taint shadow after every instruction.
+
+// +build VERIFY_EVM_TAINT_SHADOW
+
+package vm
+
+const verifyTaint = true
+
+// verifyTaintShadow panics if the taint stack or memory of a frame no longer
+// shadows its stack or memory after the operation op at pc.
+func verifyTaintShadow(op OpCode, pc uint64, stack *Stack, mem *Memory, taint_stack *TaintStack, taint_mem *TaintMemory) {
+	if err := checkTaintShadow(op, pc, stack, mem, taint_stack, taint_mem); err != nil {
+		panic(err)
+	}
+}
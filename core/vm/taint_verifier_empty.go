@@ -0,0 +1,11 @@
+// Author: Jianbo-Gao
+// Verifying the taint shadow after every instruction.
+
+// +build !VERIFY_EVM_TAINT_SHADOW
+
+package vm
+
+const verifyTaint = false
+
+func verifyTaintShadow(op OpCode, pc uint64, stack *Stack, mem *Memory, taint_stack *TaintStack, taint_mem *TaintMemory) {
+}
@@ -24,6 +24,7 @@ type GasTable struct {
 	SLoad       uint64
 	Calls       uint64
 	Suicide     uint64
+	ExtcodeHash uint64
 
 	ExpByte uint64
 
@@ -73,6 +74,20 @@ var (
 		Suicide:     5000,
 		ExpByte:     50,
 
+		CreateBySuicide: 25000,
+	}
+	// GasTableConstantinople contain the gas re-prices for
+	// the constantinople phase.
+	GasTableConstantinople = GasTable{
+		ExtcodeSize: 700,
+		ExtcodeCopy: 700,
+		ExtcodeHash: 400,
+		Balance:     400,
+		SLoad:       200,
+		Calls:       700,
+		Suicide:     5000,
+		ExpByte:     50,
+
 		CreateBySuicide: 25000,
 	}
 )
@@ -13,10 +13,10 @@
 # contract3 protected overflow (safeadd2)
 ./build/bin/evm --codefile taint_contracts/contract3.bin-runtime --input b79e70edffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff0000000000000000000000000000000000000000000000000000000000000002 --json  run
 
-# contract3 potential overflow (safeadd3)
+# contract3 potential underflow (safeadd3)
 ./build/bin/evm --codefile taint_contracts/contract3.bin-runtime --input 710419abffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff0000000000000000000000000000000000000000000000000000000000000002 --json  run
 
-# contract4 potential overflow (safesub1)
+# contract4 potential underflow (safesub1)
 ./build/bin/evm --codefile taint_contracts/contract4.bin-runtime --input 9453a85400000000000000000000000000000000000000000000000000000000000000020000000000000000000000000000000000000000000000000000000000000001 --json  run
 
 # contract4 safe (safesub1)
@@ -6,11 +6,22 @@ import config
 
 executed_ops=set()
 
-def print_res(id, input_str, last_op, taint_res):
+def print_res(id, input_str, last_op, taint_res, findings=()):
     print("[Tx %s]" % id)
     print("input: %s" % input_str)
     #print("last op: %s" % last_op)
     print("result: %s" % taint_res)
+    for finding in findings:
+        print("finding: %s at pc %d (depth %d, %s from %s): %s -> %s" % (finding["class"], finding["pc"], finding["depth"], finding["opName"], ", ".join(finding["sources"]), ", ".join(finding["operands"]), finding["result"]))
+        args = [" + ".join(ref["label"] for ref in refs) for refs in finding["args"] if refs]
+        if args:
+            print("    %s of %s" % (finding["opName"], " and ".join(args)))
+        if finding.get("sink"):
+            print("    %s severity, wrapped value reached %s" % (finding["severity"], finding["sink"]))
+        if finding.get("iterations"):
+            print("    loop jumped back %d times using %d gas" % (finding["iterations"], finding["gasUsed"]))
+        if finding.get("slots"):
+            print("    selector %s writes %s after the call" % (finding.get("selector", "-"), ", ".join(finding["slots"])))
     print("")
 
 def get_full_opnum(code_str):
@@ -29,68 +40,99 @@ def get_executed_ops(output_str):
         except:
             pass
 
-def run_evm(code_str, input_str):
-    output = os.popen("%s --code %s --input %s --json run" % (config.EVM_PATH, code_str, input_str))
+def get_findings(output_str):
+    findings = []
+    for line in output_str.splitlines():
+        if line.startswith("TaintFinding:"):
+            findings.append(json.loads(line[len("TaintFinding:"):]))
+    return findings
+
+def get_last_op(output_str):
+    for line in reversed(output_str.splitlines()):
+        try:
+            return json.loads(line)["opName"]
+        except:
+            pass
+    return None
+
+TRIGGERED = ("overflow", "underflow", "signed overflow", "reentrancy", "tainted target", "tainted jump", "unchecked call", "tx.origin authorization")
+PROTECTED = ("protected overflow", "protected underflow", "protected signed overflow")
+POTENTIAL = ("potential overflow", "potential underflow")
+# reported without an overflow, they need no retry
+INFORMATIONAL = ("tainted loop bound",)
+
+def get_arg_words(findings):
+    # calldata words holding the values of potential overflows, offsets and
+    # lengths of dynamic arguments are left alone to keep the input decodable
+    words = set()
+    for finding in findings:
+        if finding["class"] not in POTENTIAL:
+            continue
+        for refs in finding["args"]:
+            for ref in refs:
+                if not ref["label"].startswith(("offset of", "length of")):
+                    words.add(ref["word"])
+    return sorted(words)
+
+def set_arg_words(input_str, words, value):
+    for word in words:
+        start = 8 + word*64
+        if start + 64 <= len(input_str):
+            input_str = input_str[:start] + value + input_str[start+64:]
+    return input_str
+
+def evm_options(abi_path):
+    return abi_path and "--abi %s " % abi_path or ""
+
+def run_evm(code_str, input_str, abi_path=None):
+    output = os.popen("%s --code %s --input %s %s--json run" % (config.EVM_PATH, code_str, input_str, evm_options(abi_path)))
     output_str = output.read()
     get_executed_ops(output_str)
-    try:
-        last_op = json.loads(output_str.splitlines()[-3])["opName"]
-    except:
-        last_op = json.loads(output_str.splitlines()[-6])["opName"]
+    last_op = get_last_op(output_str)
     taint_res = output_str.splitlines()[-1].strip().split(":")[1][1:]
-    return last_op, taint_res
+    return last_op, taint_res, get_findings(output_str)
 
-def run_evm_with_value(code_str, input_str):
-    output = os.popen("%s --code %s --input %s --sender 0000000000000000000000000000000000000000 --value \"0xffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff\" --prestate genesis-example.json --json run" % (config.EVM_PATH, code_str, input_str))
+def run_evm_with_value(code_str, input_str, abi_path=None):
+    output = os.popen("%s --code %s --input %s --sender 0000000000000000000000000000000000000000 --value \"0xffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff\" --prestate genesis-example.json %s--json run" % (config.EVM_PATH, code_str, input_str, evm_options(abi_path)))
     output_str = output.read()
     get_executed_ops(output_str)
-    line = -3
-    last_op=None
-    while line >= -10:
-        try:
-            last_op = json.loads(output_str.splitlines()[line])["opName"]
-            break
-        except:
-            line -= 1
+    last_op = get_last_op(output_str)
     taint_res = output_str.splitlines()[-1].strip().split(":")[1][1:]
-    return last_op, taint_res
+    return last_op, taint_res, get_findings(output_str)
 
-def main(code_str, input_str, debug_flag=False):
-    last_op, taint_res = run_evm(code_str, input_str)
-    debug_flag and print_res(0, input_str, last_op, taint_res)
-    if taint_res in ("safe", "overflow", "protected overflow"):
+def main(code_str, input_str, debug_flag=False, abi_path=None):
+    last_op, taint_res, findings = run_evm(code_str, input_str, abi_path)
+    debug_flag and print_res(0, input_str, last_op, taint_res, findings)
+    if taint_res == "safe" or taint_res in TRIGGERED or taint_res in PROTECTED or taint_res in INFORMATIONAL:
         #print(last_op)
         print(taint_res)
-        if taint_res == "overflow":
+        if taint_res in TRIGGERED:
             return True, last_op, taint_res, None
         else:
             return False, last_op, taint_res, None
 
-    elif taint_res == "potential overflow":
-        last_op_with_value, taint_res_with_value = run_evm_with_value(code_str, input_str)
-        debug_flag and print_res("0 with value", input_str, last_op_with_value, taint_res_with_value)
-        if taint_res_with_value == "overflow":
+    elif taint_res in POTENTIAL:
+        last_op_with_value, taint_res_with_value, findings_with_value = run_evm_with_value(code_str, input_str, abi_path)
+        debug_flag and print_res("0 with value", input_str, last_op_with_value, taint_res_with_value, findings_with_value)
+        if taint_res_with_value in TRIGGERED:
             #print(last_op_with_value)
             print("retry: potential overflow triggered")
             return True, last_op_with_value, taint_res_with_value, None
 
-        arg_num = (len(input_str)-8)/64
-        input_args = ("0000000000000000000000000000000000000000000000000000000000000000", "ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff")
-        input_method = input_str[0:8]
-
-        retry_sum = 2 ** arg_num
-        retry_id = 0
-        while retry_id < retry_sum:
-            input_str = input_method
-            arg_id = 0
-            while arg_id < arg_num:
-                input_str += input_args[(retry_id>>arg_id)%2]
-                arg_id += 1
-
-            retry_id += 1
-            retry_last_op, retry_taint_res = run_evm(code_str, input_str)
-            debug_flag and print_res(retry_id, input_str, retry_last_op, retry_taint_res)
-            if retry_taint_res == "overflow":
+        # only retry with the arguments that reached a potential overflow:
+        # all of them at their maximum, then each one at its extremes
+        words = get_arg_words(findings + findings_with_value)
+        word_min = "0000000000000000000000000000000000000000000000000000000000000000"
+        word_max = "ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff"
+        retry_inputs = [set_arg_words(input_str, words, word_max)]
+        for word in words:
+            retry_inputs.append(set_arg_words(input_str, [word], word_max))
+            retry_inputs.append(set_arg_words(input_str, [word], word_min))
+
+        for retry_id, retry_input in enumerate(retry_inputs, 1):
+            retry_last_op, retry_taint_res, retry_findings = run_evm(code_str, retry_input, abi_path)
+            debug_flag and print_res(retry_id, retry_input, retry_last_op, retry_taint_res, retry_findings)
+            if retry_taint_res in TRIGGERED:
                 #print(retry_last_op)
                 retry_result = "retry: potential overflow triggered"
                 print(retry_result)
@@ -113,6 +155,7 @@ def init_parser(help=False):
     parser.add_argument('-d', '--debug', action="store_true", help='print debug info')
     parser.add_argument('-c', '--code', help='runtime bytecode')
     parser.add_argument('-i', '--input', help='input data')
+    parser.add_argument('-a', '--abi', help='JSON file with the contract ABI, names the arguments in findings')
     return parser
 
 def demo():
@@ -134,7 +177,7 @@ if __name__ == '__main__':
     if args.demo and not (args.code or args.input):
         demo()
     elif (not args.demo) and args.code and args.input:
-        main(args.code, args.input, args.debug)
+        main(args.code, args.input, args.debug, args.abi)
         print("Coverage: %.2f%%" % (len(executed_ops)*100.00/get_full_opnum(args.code)))
     else:
         parser.print_help()